@@ -3,6 +3,7 @@ package main
 import (
 	_ "library/cmd/docs"
 	"library/config"
+	"library/internal/policy"
 	"library/postgres"
 	"library/run"
 	"os"
@@ -21,6 +22,7 @@ import (
 // @securityDefinitions.apikey	ApiKeyAuth
 // @in							header
 // @name						Authorization
+// @description				Bearer access token from /auth/login
 func main() {
 	logger := logging.GetLogger()
 
@@ -29,10 +31,28 @@ func main() {
 		logger.Fatal("Failed to load DB config: ", zap.Error(err))
 	}
 
+	libConf, err := config.LoadLibraryConfig()
+	if err != nil {
+		logger.Fatal("Failed to load library config: ", zap.Error(err))
+	}
+
+	authConf, err := config.LoadAuthConfig()
+	if err != nil {
+		logger.Fatal("Failed to load auth config: ", zap.Error(err))
+	}
+
+	policyConf, err := policy.LoadConfig(libConf.PolicyFile)
+	if err != nil {
+		logger.Fatal("Failed to load checkout policy: ", zap.Error(err))
+	}
+
 	db := postgres.NewPostgresDB(conf, logger)
 	defer db.Close()
 
-	app := run.NewApp(db, logger)
+	app := run.NewApp(db, libConf, authConf, policyConf, logger)
+	if len(os.Args) > 1 {
+		os.Exit(app.Command(os.Args[1:]))
+	}
 
 	exitCode := app.
 		Bootstrap().
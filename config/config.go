@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,9 +22,34 @@ type DBConfig struct {
 	SSLMode  string
 }
 
+type LibraryConfig struct {
+	LoanPeriod time.Duration
+	// FinePerDay - штраф за день просрочки в копейках
+	FinePerDay int64
+	// MaxBalance - долг, при превышении которого книги не выдаются
+	MaxBalance int64
+	// HoldPickupWindow - сколько отложенная книга ждёт читателя на полке
+	HoldPickupWindow time.Duration
+	// MaxRenewals - сколько раз можно продлить одну выдачу
+	MaxRenewals int
+	// PolicyFile - файл с правилами выдачи книг
+	PolicyFile string
+}
+
+type AuthConfig struct {
+	AccessSecret  string
+	RefreshSecret string
+	AccessTTL     time.Duration
+	RefreshTTL    time.Duration
+	// AdminEmail и AdminPassword - учётная запись библиотекаря, создаваемая при старте
+	AdminEmail    string
+	AdminPassword string
+}
+
 func LoadDBConfig() (*DBConfig, error) {
+	// в контейнере переменные приходят из env_file docker compose, файла .env там нет
 	err := godotenv.Load()
-	if err != nil {
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		log.Fatal("Error loading .env file")
 		return nil, err
 	}
@@ -38,3 +68,123 @@ func (c *DBConfig) GetDBURL() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
 }
+
+// LoadLibraryConfig - правила выдачи книг; переменные окружения уже загружены LoadDBConfig
+func LoadLibraryConfig() (*LibraryConfig, error) {
+	loanDays, err := getEnvInt("LOAN_PERIOD_DAYS", 14)
+	if err != nil {
+		return nil, err
+	}
+	finePerDay, err := getEnvInt("FINE_PER_DAY", 10)
+	if err != nil {
+		return nil, err
+	}
+	maxBalance, err := getEnvInt("MAX_OUTSTANDING_BALANCE", 500)
+	if err != nil {
+		return nil, err
+	}
+	pickupDays, err := getEnvInt("HOLD_PICKUP_DAYS", 3)
+	if err != nil {
+		return nil, err
+	}
+	maxRenewals, err := getEnvInt("MAX_RENEWALS", 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LibraryConfig{
+		LoanPeriod:       time.Duration(loanDays) * 24 * time.Hour,
+		FinePerDay:       int64(finePerDay),
+		MaxBalance:       int64(maxBalance),
+		HoldPickupWindow: time.Duration(pickupDays) * 24 * time.Hour,
+		MaxRenewals:      maxRenewals,
+		PolicyFile:       getEnv("POLICY_FILE", "policy.json"),
+	}, nil
+}
+
+// LoadAuthConfig - ключи и сроки жизни JWT токенов
+func LoadAuthConfig() (*AuthConfig, error) {
+	accessSecret := os.Getenv("JWT_SECRET")
+	refreshSecret := os.Getenv("JWT_REFRESH_SECRET")
+	if err := checkSecret("JWT_SECRET", accessSecret); err != nil {
+		return nil, err
+	}
+	if err := checkSecret("JWT_REFRESH_SECRET", refreshSecret); err != nil {
+		return nil, err
+	}
+	if accessSecret == refreshSecret {
+		return nil, fmt.Errorf("JWT_SECRET and JWT_REFRESH_SECRET must differ")
+	}
+	adminPassword := os.Getenv("ADMIN_PASSWORD")
+	if adminPassword != "" && (len(adminPassword) < minAdminPassword || isPlaceholder(adminPassword)) {
+		return nil, fmt.Errorf("ADMIN_PASSWORD must be at least %d characters and not a placeholder", minAdminPassword)
+	}
+
+	accessMinutes, err := getEnvInt("JWT_ACCESS_TTL_MINUTES", 15)
+	if err != nil {
+		return nil, err
+	}
+	refreshHours, err := getEnvInt("JWT_REFRESH_TTL_HOURS", 720)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthConfig{
+		AccessSecret:  accessSecret,
+		RefreshSecret: refreshSecret,
+		AccessTTL:     time.Duration(accessMinutes) * time.Minute,
+		RefreshTTL:    time.Duration(refreshHours) * time.Hour,
+		AdminEmail:    os.Getenv("ADMIN_EMAIL"),
+		AdminPassword: adminPassword,
+	}, nil
+}
+
+const (
+	minSecretLength  = 32
+	minAdminPassword = 12
+)
+
+// placeholders - значения из примеров и старых версий .env, которые нельзя оставлять в рабочей конфигурации
+var placeholders = []string{"change-me", "changeme", "secret", "password", "librarian", "admin", "example"}
+
+func checkSecret(key, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s must be set", key)
+	}
+	if len(value) < minSecretLength {
+		return fmt.Errorf("%s must be at least %d bytes long", key, minSecretLength)
+	}
+	if isPlaceholder(value) {
+		return fmt.Errorf("%s looks like a placeholder, generate a random value", key)
+	}
+	return nil
+}
+
+func isPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	for _, p := range placeholders {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func getEnvInt(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"errors"
+	"library/internal/domain"
+	"library/responder"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+var (
+	ErrMissingToken  = errors.New("missing bearer token or api key")
+	ErrForbiddenRole = errors.New("insufficient role")
+	ErrScope         = errors.New("api key scope does not allow this request")
+)
+
+type Middleware struct {
+	tokens    *TokenManager
+	keys      APIKeyValidator
+	responder responder.Responder
+	logger    *zap.Logger
+}
+
+func NewMiddleware(tokens *TokenManager, keys APIKeyValidator, responder responder.Responder, logger *zap.Logger) *Middleware {
+	return &Middleware{tokens: tokens, keys: keys, responder: responder, logger: logger}
+}
+
+// Authenticate проверяет Bearer токен или API ключ и кладёт клиента в контекст запроса
+func (m *Middleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := apiKey(r); key != "" {
+			m.authenticateKey(w, r, next, key)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			m.responder.ErrorUnauthorized(w, r, ErrMissingToken)
+			return
+		}
+
+		principal, err := m.tokens.ParseAccess(token)
+		if err != nil {
+			m.responder.ErrorUnauthorized(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// authenticateKey пропускает запрос машинного клиента и пишет его в журнал аудита
+func (m *Middleware) authenticateKey(w http.ResponseWriter, r *http.Request, next http.Handler, plain string) {
+	key, err := m.keys.Authenticate(r.Context(), plain)
+	if errors.Is(err, ErrInvalidAPIKey) {
+		m.logger.Warn("api key rejected",
+			zap.String("remote_addr", r.RemoteAddr),
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path))
+		m.responder.ErrorUnauthorized(w, r, err)
+		return
+	}
+	if err != nil {
+		m.responder.Error(w, r, err)
+		return
+	}
+
+	principal := &Principal{Role: domain.RoleService, APIKeyID: key.ID, Scopes: key.Scopes}
+	ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+	start := time.Now()
+
+	next.ServeHTTP(ww, r.WithContext(WithPrincipal(r.Context(), principal)))
+
+	m.logger.Info("api key request",
+		zap.Int("api_key_id", key.ID),
+		zap.String("api_key_name", key.Name),
+		zap.String("api_key_prefix", key.Prefix),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.Int("status", ww.Status()),
+		zap.Duration("duration", time.Since(start)))
+}
+
+func apiKey(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	// без префикса ApiKey заголовок Authorization относится к Bearer токену
+	if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "ApiKey "); ok {
+		return key
+	}
+	return ""
+}
+
+func (m *Middleware) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFrom(r.Context())
+			if !ok {
+				m.responder.ErrorUnauthorized(w, r, ErrMissingToken)
+				return
+			}
+			if !slices.Contains(roles, principal.Role) {
+				m.responder.ErrorForbidden(w, r, ErrForbiddenRole)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope ограничивает API ключи группой маршрутов; область read разрешает любые GET запросы
+func (m *Middleware) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFrom(r.Context())
+			if !ok {
+				m.responder.ErrorUnauthorized(w, r, ErrMissingToken)
+				return
+			}
+			readOnly := r.Method == http.MethodGet || r.Method == http.MethodHead
+			if !principal.HasScope(scope) && !(readOnly && principal.HasScope(domain.ScopeRead)) {
+				m.responder.ErrorForbidden(w, r, ErrScope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"library/internal/domain"
+	"library/responder"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/ptflp/godecoder"
+	"go.uber.org/zap"
+)
+
+const testAPIKey = "lib_test_key"
+
+// fakeKeys принимает только testAPIKey
+type fakeKeys struct{}
+
+func (fakeKeys) Authenticate(_ context.Context, key string) (*domain.APIKey, error) {
+	if key != testAPIKey {
+		return nil, ErrInvalidAPIKey
+	}
+	return &domain.APIKey{ID: 3, Name: "test", Scopes: []string{domain.ScopeRead}}, nil
+}
+
+func newTestMiddleware() *Middleware {
+	respond := responder.NewResponder(godecoder.NewDecoder(jsoniter.Config{}), zap.NewNop())
+	return NewMiddleware(newTestTokens(), fakeKeys{}, respond, zap.NewNop())
+}
+
+// serve прогоняет запрос через обработчик и возвращает статус ответа и клиента, дошедшего до обработчика
+func serve(h func(http.Handler) http.Handler, r *http.Request) (int, *Principal) {
+	var seen *Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = PrincipalFrom(r.Context())
+	})
+	w := httptest.NewRecorder()
+	h(next).ServeHTTP(w, r)
+	return w.Code, seen
+}
+
+func TestAuthenticate(t *testing.T) {
+	mw := newTestMiddleware()
+	pair, err := newTestTokens().Issue(&domain.User{ID: 7, Role: domain.RolePatron})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+		role   string
+	}{
+		{"access token", "Bearer " + pair.AccessToken, http.StatusOK, domain.RolePatron},
+		{"api key", "ApiKey " + testAPIKey, http.StatusOK, domain.RoleService},
+		{"unknown api key", "ApiKey other", http.StatusUnauthorized, ""},
+		{"refresh token", "Bearer " + pair.RefreshToken, http.StatusUnauthorized, ""},
+		{"missing header", "", http.StatusUnauthorized, ""},
+		{"empty bearer", "Bearer ", http.StatusUnauthorized, ""},
+		{"other scheme", "Basic " + pair.AccessToken, http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/book", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			code, principal := serve(mw.Authenticate, r)
+			if code != tt.want {
+				t.Fatalf("status %d, want %d", code, tt.want)
+			}
+			if code == http.StatusOK && (principal == nil || principal.Role != tt.role) {
+				t.Fatalf("principal %+v, want role %s", principal, tt.role)
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	mw := newTestMiddleware()
+	librarian := mw.RequireRole(domain.RoleLibrarian, domain.RoleService)
+	tests := []struct {
+		name      string
+		principal *Principal
+		want      int
+	}{
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"patron", &Principal{UserID: 1, Role: domain.RolePatron}, http.StatusForbidden},
+		{"librarian", &Principal{UserID: 2, Role: domain.RoleLibrarian}, http.StatusOK},
+		{"service", &Principal{APIKeyID: 3, Role: domain.RoleService}, http.StatusOK},
+		{"unknown role", &Principal{UserID: 4, Role: "admin"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/book/1", nil)
+			if tt.principal != nil {
+				r = r.WithContext(WithPrincipal(r.Context(), tt.principal))
+			}
+			if code, _ := serve(librarian, r); code != tt.want {
+				t.Fatalf("status %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanActFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *Principal
+		userID    int
+		want      bool
+	}{
+		{"anonymous", nil, 1, false},
+		{"patron for self", &Principal{UserID: 1, Role: domain.RolePatron}, 1, true},
+		{"patron for another", &Principal{UserID: 1, Role: domain.RolePatron}, 2, false},
+		{"librarian", &Principal{UserID: 9, Role: domain.RoleLibrarian}, 2, true},
+		{"service", &Principal{APIKeyID: 3, Role: domain.RoleService}, 2, true},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.principal != nil {
+			ctx = WithPrincipal(ctx, tt.principal)
+		}
+		if got := CanActFor(ctx, tt.userID); got != tt.want {
+			t.Errorf("%s: CanActFor(%d) = %v, want %v", tt.name, tt.userID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"library/internal/domain"
+	"slices"
+)
+
+var ErrNotOwner = errors.New("patrons may act only on their own account")
+
+// Principal - аутентифицированный пользователь или машинный клиент запроса
+type Principal struct {
+	UserID   int
+	Role     string
+	APIKeyID int
+	Scopes   []string
+}
+
+// HasScope - пользователям с токеном области не назначаются, их права определяет роль
+func (p *Principal) HasScope(scope string) bool {
+	return p.APIKeyID == 0 || slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// CanActFor - библиотекарь и машинный клиент действуют за любого читателя, читатель только за себя
+func CanActFor(ctx context.Context, userID int) bool {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return false
+	}
+	return p.Role == domain.RoleLibrarian || p.Role == domain.RoleService || p.UserID == userID
+}
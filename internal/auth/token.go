@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"library/config"
+	"library/internal/domain"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	issuer = "library"
+
+	tokenAccess  = "access"
+	tokenRefresh = "refresh"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+type Claims struct {
+	Role string `json:"role,omitempty"`
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// TokenManager выпускает и проверяет HMAC-подписанные токены; access и refresh подписываются разными ключами
+type TokenManager struct {
+	accessKey  []byte
+	refreshKey []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenManager(conf *config.AuthConfig) *TokenManager {
+	return &TokenManager{
+		accessKey:  []byte(conf.AccessSecret),
+		refreshKey: []byte(conf.RefreshSecret),
+		accessTTL:  conf.AccessTTL,
+		refreshTTL: conf.RefreshTTL,
+	}
+}
+
+func (m *TokenManager) Issue(user *domain.User) (*TokenPair, error) {
+	now := time.Now()
+
+	access, err := m.sign(m.accessKey, Claims{
+		Role:             user.Role,
+		Type:             tokenAccess,
+		RegisteredClaims: m.registered(user.ID, now, m.accessTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := m.sign(m.refreshKey, Claims{
+		Type:             tokenRefresh,
+		RegisteredClaims: m.registered(user.ID, now, m.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(m.accessTTL.Seconds()),
+	}, nil
+}
+
+func (m *TokenManager) ParseAccess(token string) (*Principal, error) {
+	claims, err := m.parse(m.accessKey, token, tokenAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return &Principal{UserID: userID, Role: claims.Role}, nil
+}
+
+// ParseRefresh возвращает ID пользователя из refresh токена
+func (m *TokenManager) ParseRefresh(token string) (int, error) {
+	claims, err := m.parse(m.refreshKey, token, tokenRefresh)
+	if err != nil {
+		return 0, err
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return userID, nil
+}
+
+func (m *TokenManager) registered(userID int, now time.Time, ttl time.Duration) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    issuer,
+		Subject:   strconv.Itoa(userID),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+}
+
+func (m *TokenManager) sign(key []byte, claims Claims) (string, error) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return token, nil
+}
+
+func (m *TokenManager) parse(key []byte, token, tokenType string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
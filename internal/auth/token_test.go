@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"errors"
+	"library/config"
+	"library/internal/domain"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testAccessSecret  = "access-0123456789abcdef0123456789abcdef"
+	testRefreshSecret = "refresh-0123456789abcdef0123456789abcdef"
+)
+
+func newTestTokens() *TokenManager {
+	return NewTokenManager(&config.AuthConfig{
+		AccessSecret:  testAccessSecret,
+		RefreshSecret: testRefreshSecret,
+		AccessTTL:     15 * time.Minute,
+		RefreshTTL:    24 * time.Hour,
+	})
+}
+
+// signed - токен с произвольными заявками, подписанный ключом key
+func signed(t *testing.T, method jwt.SigningMethod, key interface{}, claims Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func claims(typ, iss string, exp time.Duration) Claims {
+	now := time.Now()
+	c := Claims{Role: domain.RoleLibrarian, Type: typ, RegisteredClaims: jwt.RegisteredClaims{
+		Issuer:   iss,
+		Subject:  strconv.Itoa(42),
+		IssuedAt: jwt.NewNumericDate(now),
+	}}
+	if exp != 0 {
+		c.ExpiresAt = jwt.NewNumericDate(now.Add(exp))
+	}
+	return c
+}
+
+func TestIssueAndParse(t *testing.T) {
+	tokens := newTestTokens()
+	pair, err := tokens.Issue(&domain.User{ID: 42, Role: domain.RoleLibrarian})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	principal, err := tokens.ParseAccess(pair.AccessToken)
+	if err != nil || principal.UserID != 42 || principal.Role != domain.RoleLibrarian {
+		t.Fatalf("ParseAccess() = %+v, %v", principal, err)
+	}
+	userID, err := tokens.ParseRefresh(pair.RefreshToken)
+	if err != nil || userID != 42 {
+		t.Fatalf("ParseRefresh() = %d, %v", userID, err)
+	}
+
+	// токены одного вида не принимаются вместо другого
+	if _, err := tokens.ParseAccess(pair.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("refresh token accepted as access: %v", err)
+	}
+	if _, err := tokens.ParseRefresh(pair.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("access token accepted as refresh: %v", err)
+	}
+}
+
+func TestParseAccessRejects(t *testing.T) {
+	access := []byte(testAccessSecret)
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"refresh type signed with access key", signed(t, jwt.SigningMethodHS256, access, claims(tokenRefresh, issuer, time.Hour))},
+		{"missing type", signed(t, jwt.SigningMethodHS256, access, claims("", issuer, time.Hour))},
+		{"signed with refresh key", signed(t, jwt.SigningMethodHS256, []byte(testRefreshSecret), claims(tokenAccess, issuer, time.Hour))},
+		{"foreign issuer", signed(t, jwt.SigningMethodHS256, access, claims(tokenAccess, "other", time.Hour))},
+		{"missing issuer", signed(t, jwt.SigningMethodHS256, access, claims(tokenAccess, "", time.Hour))},
+		{"HS512", signed(t, jwt.SigningMethodHS512, access, claims(tokenAccess, issuer, time.Hour))},
+		{"alg none", signed(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, claims(tokenAccess, issuer, time.Hour))},
+		{"expired", signed(t, jwt.SigningMethodHS256, access, claims(tokenAccess, issuer, -time.Minute))},
+		{"without expiry", signed(t, jwt.SigningMethodHS256, access, claims(tokenAccess, issuer, 0))},
+		{"garbage", "not.a.token"},
+		{"empty", ""},
+	}
+	tokens := newTestTokens()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if principal, err := tokens.ParseAccess(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("ParseAccess() = %+v, %v, want ErrInvalidToken", principal, err)
+			}
+		})
+	}
+}
+
+func TestParseAccessTampered(t *testing.T) {
+	tokens := newTestTokens()
+	pair, err := tokens.Issue(&domain.User{ID: 1, Role: domain.RolePatron})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// подпись последнего символа меняется, заявки остаются прежними
+	tampered := []byte(pair.AccessToken)
+	last := len(tampered) - 1
+	if tampered[last] == 'A' {
+		tampered[last] = 'B'
+	} else {
+		tampered[last] = 'A'
+	}
+	if _, err := tokens.ParseAccess(string(tampered)); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("tampered token accepted: %v", err)
+	}
+}
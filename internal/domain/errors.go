@@ -1,19 +1,383 @@
 package domain
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Виды ошибок; конкретные ошибки относятся к своему виду через метод Is,
+// а метод Code даёт стабильный код ошибки для клиентов API
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrValidation  = errors.New("validation failed")
+	ErrForbidden   = errors.New("forbidden")
+	ErrUnavailable = errors.New("service unavailable")
+)
 
 type ErrAuthorNotFound struct {
 	AuthorID int
+	Name     string
 }
 
 func (e *ErrAuthorNotFound) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("author %q not found", e.Name)
+	}
 	return fmt.Sprintf("author with ID %d not found", e.AuthorID)
 }
 
+func (e *ErrAuthorNotFound) Is(target error) bool { return target == ErrNotFound }
+
+func (e *ErrAuthorNotFound) Code() string { return "author_not_found" }
+
 type ErrBookNotFound struct {
 	BookID int
+	ISBN   string
 }
 
 func (e *ErrBookNotFound) Error() string {
+	if e.ISBN != "" {
+		return fmt.Sprintf("book with ISBN %s not found", e.ISBN)
+	}
 	return fmt.Sprintf("book with ID %d not found", e.BookID)
 }
+
+func (e *ErrBookNotFound) Is(target error) bool { return target == ErrNotFound }
+
+func (e *ErrBookNotFound) Code() string { return "book_not_found" }
+
+// ErrDuplicateISBN - ISBN уже принадлежит другой книге каталога
+type ErrDuplicateISBN struct {
+	ISBN   string
+	BookID int
+}
+
+func (e *ErrDuplicateISBN) Error() string {
+	if e.BookID != 0 {
+		return fmt.Sprintf("ISBN %s already belongs to book with ID %d", e.ISBN, e.BookID)
+	}
+	return fmt.Sprintf("ISBN %s already belongs to another book", e.ISBN)
+}
+
+func (e *ErrDuplicateISBN) Is(target error) bool { return target == ErrConflict }
+
+func (e *ErrDuplicateISBN) Code() string { return "duplicate_isbn" }
+
+type ErrUserNotFound struct {
+	UserID int
+	Email  string
+}
+
+func (e *ErrUserNotFound) Error() string {
+	if e.Email != "" {
+		return fmt.Sprintf("user with email %s not found", e.Email)
+	}
+	return fmt.Sprintf("user with ID %d not found", e.UserID)
+}
+
+func (e *ErrUserNotFound) Is(target error) bool { return target == ErrNotFound }
+
+func (e *ErrUserNotFound) Code() string { return "user_not_found" }
+
+type ErrBookNotAvailable struct {
+	BookID int
+}
+
+func (e *ErrBookNotAvailable) Error() string {
+	return fmt.Sprintf("book with ID %d is not available", e.BookID)
+}
+
+func (e *ErrBookNotAvailable) Is(target error) bool { return target == ErrConflict }
+
+func (e *ErrBookNotAvailable) Code() string { return "book_not_available" }
+
+// ErrAlreadyRented - у читателя уже есть экземпляр этой книги
+type ErrAlreadyRented struct {
+	BookID int
+	UserID int
+}
+
+func (e *ErrAlreadyRented) Error() string {
+	return fmt.Sprintf("user with ID %d already has book with ID %d", e.UserID, e.BookID)
+}
+
+func (e *ErrAlreadyRented) Is(target error) bool { return target == ErrConflict }
+
+func (e *ErrAlreadyRented) Code() string { return "already_rented" }
+
+type ErrCopyNotFound struct {
+	CopyID  int
+	Barcode string
+}
+
+func (e *ErrCopyNotFound) Error() string {
+	if e.Barcode != "" {
+		return fmt.Sprintf("copy with barcode %s not found", e.Barcode)
+	}
+	return fmt.Sprintf("copy with ID %d not found", e.CopyID)
+}
+
+func (e *ErrCopyNotFound) Is(target error) bool { return target == ErrNotFound }
+
+func (e *ErrCopyNotFound) Code() string { return "copy_not_found" }
+
+// ErrCopyInUse - экземпляр выдан, отложен по брони или уже списан, поэтому списать его нельзя
+type ErrCopyInUse struct {
+	CopyID int
+	Reason string
+}
+
+func (e *ErrCopyInUse) Error() string {
+	return fmt.Sprintf("copy with ID %d is in use: %s", e.CopyID, e.Reason)
+}
+
+func (e *ErrCopyInUse) Is(target error) bool { return target == ErrConflict }
+
+func (e *ErrCopyInUse) Code() string { return "copy_in_use" }
+
+type ErrRentalNotFound struct {
+	RentalID int
+	BookID   int
+	CopyID   int
+}
+
+func (e *ErrRentalNotFound) Error() string {
+	if e.RentalID != 0 {
+		return fmt.Sprintf("rental with ID %d not found", e.RentalID)
+	}
+	if e.CopyID != 0 {
+		return fmt.Sprintf("no active rental for copy with ID %d", e.CopyID)
+	}
+	return fmt.Sprintf("no active rental for book with ID %d", e.BookID)
+}
+
+func (e *ErrRentalNotFound) Is(target error) bool { return target == ErrNotFound }
+
+func (e *ErrRentalNotFound) Code() string { return "rental_not_found" }
+
+type ErrBalanceExceeded struct {
+	UserID  int
+	Balance int64
+	Limit   int64
+}
+
+func (e *ErrBalanceExceeded) Error() string {
+	return fmt.Sprintf("user with ID %d owes %d, limit is %d", e.UserID, e.Balance, e.Limit)
+}
+
+func (e *ErrBalanceExceeded) Is(target error) bool { return target == ErrForbidden }
+
+func (e *ErrBalanceExceeded) Code() string { return "balance_exceeded" }
+
+type ErrInvalidAmount struct {
+	Amount int64
+	Reason string
+}
+
+func (e *ErrInvalidAmount) Error() string {
+	return fmt.Sprintf("invalid amount %d: %s", e.Amount, e.Reason)
+}
+
+func (e *ErrInvalidAmount) Is(target error) bool { return target == ErrValidation }
+
+func (e *ErrInvalidAmount) Code() string { return "invalid_amount" }
+
+type ErrHoldNotFound struct {
+	HoldID int
+}
+
+func (e *ErrHoldNotFound) Error() string {
+	return fmt.Sprintf("hold with ID %d not found", e.HoldID)
+}
+
+func (e *ErrHoldNotFound) Is(target error) bool { return target == ErrNotFound }
+
+func (e *ErrHoldNotFound) Code() string { return "hold_not_found" }
+
+type ErrHoldNotAllowed struct {
+	BookID int
+	Reason string
+}
+
+func (e *ErrHoldNotAllowed) Error() string {
+	return fmt.Sprintf("cannot hold book with ID %d: %s", e.BookID, e.Reason)
+}
+
+func (e *ErrHoldNotAllowed) Is(target error) bool { return target == ErrConflict }
+
+func (e *ErrHoldNotAllowed) Code() string { return "hold_not_allowed" }
+
+type ErrRenewalNotAllowed struct {
+	RentalID int
+	Reason   string
+}
+
+func (e *ErrRenewalNotAllowed) Error() string {
+	return fmt.Sprintf("cannot renew rental with ID %d: %s", e.RentalID, e.Reason)
+}
+
+func (e *ErrRenewalNotAllowed) Is(target error) bool { return target == ErrConflict }
+
+func (e *ErrRenewalNotAllowed) Code() string { return "renewal_not_allowed" }
+
+type ErrPolicyDenied struct {
+	BookID     int
+	UserID     int
+	Violations []PolicyViolation
+}
+
+func (e *ErrPolicyDenied) Error() string {
+	codes := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		codes = append(codes, v.Code)
+	}
+	return fmt.Sprintf("checkout of book %d by user %d denied: %s", e.BookID, e.UserID, strings.Join(codes, ", "))
+}
+
+func (e *ErrPolicyDenied) Is(target error) bool { return target == ErrForbidden }
+
+func (e *ErrPolicyDenied) Code() string { return "policy_denied" }
+
+// Details - нарушенные правила выдачи, отдаются клиенту вместе с ошибкой
+func (e *ErrPolicyDenied) Details() interface{} { return e.Violations }
+
+type ErrInvalidCredentials struct{}
+
+func (e *ErrInvalidCredentials) Error() string {
+	return "invalid email or password"
+}
+
+func (e *ErrInvalidCredentials) Code() string { return "invalid_credentials" }
+
+type ErrAPIKeyNotFound struct {
+	KeyID int
+}
+
+func (e *ErrAPIKeyNotFound) Error() string {
+	return fmt.Sprintf("api key with ID %d not found", e.KeyID)
+}
+
+func (e *ErrAPIKeyNotFound) Is(target error) bool { return target == ErrNotFound }
+
+func (e *ErrAPIKeyNotFound) Code() string { return "api_key_not_found" }
+
+type ErrInvalidScope struct {
+	Scope string
+}
+
+func (e *ErrInvalidScope) Error() string {
+	if e.Scope == "" {
+		return "api key needs at least one scope"
+	}
+	return fmt.Sprintf("unknown api key scope %q", e.Scope)
+}
+
+func (e *ErrInvalidScope) Is(target error) bool { return target == ErrValidation }
+
+func (e *ErrInvalidScope) Code() string { return "invalid_scope" }
+
+// ErrDuplicate - нарушение уникальности
+type ErrDuplicate struct {
+	Constraint string
+	Err        error
+}
+
+func (e *ErrDuplicate) Error() string {
+	return fmt.Sprintf("duplicate value violates %s", e.Constraint)
+}
+
+func (e *ErrDuplicate) Unwrap() error { return e.Err }
+
+func (e *ErrDuplicate) Is(target error) bool { return target == ErrConflict }
+
+func (e *ErrDuplicate) Code() string { return "duplicate" }
+
+// ErrStillReferenced - удаляемая запись используется другими записями
+type ErrStillReferenced struct {
+	Constraint string
+	Err        error
+}
+
+func (e *ErrStillReferenced) Error() string {
+	return fmt.Sprintf("record is still referenced by %s", e.Constraint)
+}
+
+func (e *ErrStillReferenced) Unwrap() error { return e.Err }
+
+func (e *ErrStillReferenced) Is(target error) bool { return target == ErrConflict }
+
+func (e *ErrStillReferenced) Code() string { return "still_referenced" }
+
+// ErrInvalidReference - запись ссылается на несуществующую запись
+type ErrInvalidReference struct {
+	Constraint string
+	Err        error
+}
+
+func (e *ErrInvalidReference) Error() string {
+	return fmt.Sprintf("referenced record does not exist: %s", e.Constraint)
+}
+
+func (e *ErrInvalidReference) Unwrap() error { return e.Err }
+
+func (e *ErrInvalidReference) Is(target error) bool { return target == ErrValidation }
+
+func (e *ErrInvalidReference) Code() string { return "invalid_reference" }
+
+// ErrInvalidData - значение отвергнуто ограничениями базы
+type ErrInvalidData struct {
+	Reason string
+	Err    error
+}
+
+func (e *ErrInvalidData) Error() string {
+	return fmt.Sprintf("invalid data: %s", e.Reason)
+}
+
+func (e *ErrInvalidData) Unwrap() error { return e.Err }
+
+func (e *ErrInvalidData) Is(target error) bool { return target == ErrValidation }
+
+func (e *ErrInvalidData) Code() string { return "invalid_data" }
+
+// ErrStorageUnavailable - база данных недоступна, запрос можно повторить позже
+type ErrStorageUnavailable struct {
+	Err error
+}
+
+func (e *ErrStorageUnavailable) Error() string {
+	return fmt.Sprintf("storage unavailable: %v", e.Err)
+}
+
+func (e *ErrStorageUnavailable) Unwrap() error { return e.Err }
+
+func (e *ErrStorageUnavailable) Is(target error) bool { return target == ErrUnavailable }
+
+func (e *ErrStorageUnavailable) Code() string { return "storage_unavailable" }
+
+// FieldError - ошибка значения отдельного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ErrInvalidFields - одно или несколько полей запроса не прошли проверку
+type ErrInvalidFields struct {
+	Fields []FieldError
+}
+
+func (e *ErrInvalidFields) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return "invalid fields: " + strings.Join(msgs, "; ")
+}
+
+func (e *ErrInvalidFields) Is(target error) bool { return target == ErrValidation }
+
+func (e *ErrInvalidFields) Code() string { return "invalid_fields" }
+
+func (e *ErrInvalidFields) FieldErrors() []FieldError { return e.Fields }
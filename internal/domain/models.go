@@ -13,31 +13,161 @@ type Author struct {
 }
 
 type Book struct {
-	ID        int       `db:"id"`
-	Title     string    `db:"title"`
-	AuthorID  int       `db:"author_id"`
-	Author    *Author   `db:"author"`
-	Available bool      `db:"available"`
-	CreatedAt time.Time `db:"created_at" swaggertype:"string" format:"date-time"`
+	ID              int       `db:"id"`
+	Title           string    `db:"title"`
+	AuthorID        int       `db:"author_id"`
+	Author          *Author   `db:"author"`
+	Available       bool      `db:"available"`
+	Genre           string    `db:"genre"`
+	LoanPeriodDays  *int      `db:"loan_period_days"`
+	ISBN            *string   `db:"isbn"`
+	PublicationYear *int      `db:"publication_year"`
+	CreatedAt       time.Time `db:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+// Copy - физический экземпляр книги со своим штрихкодом; списанный экземпляр не выдаётся
+type Copy struct {
+	ID        int        `db:"id"`
+	BookID    int        `db:"book_id"`
+	Barcode   string     `db:"barcode"`
+	Available bool       `db:"available"`
+	RetiredAt *time.Time `db:"retired_at"`
+	CreatedAt time.Time  `db:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+// IsGeneratedBarcode - штрихкод вида B<номер книги из 9 цифр>[-n]. Такие штрихкоды выдаёт сама система
+// (миграция 000013, импорт каталога), поэтому вручную они не принимаются
+func IsGeneratedBarcode(barcode string) bool {
+	if len(barcode) < 10 || barcode[0] != 'B' || !digits(barcode[1:10]) {
+		return false
+	}
+	rest := barcode[10:]
+	return rest == "" || len(rest) > 1 && rest[0] == '-' && digits(rest[1:])
+}
+
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
 }
 
+const (
+	RolePatron    = "patron"
+	RoleLibrarian = "librarian"
+	// RoleService - машинный клиент с API ключом, права определяются областями ключа
+	RoleService = "service"
+)
+
 type User struct {
-	ID          int          `db:"id"`
-	Name        string       `db:"name"`
-	Email       string       `db:"email"`
-	CreatedAt   time.Time    `db:"created_at" swaggertype:"string" format:"date-time"`
-	RentedBooks []BookRental `db:"rented_books"`
+	ID           int          `db:"id"`
+	Name         string       `db:"name"`
+	Email        string       `db:"email"`
+	Role         string       `db:"role"`
+	Password     string       `db:"-" json:"-"`
+	PasswordHash string       `db:"password_hash" json:"-"`
+	BirthDate    *time.Time   `db:"birth_date" swaggertype:"string" format:"date"`
+	CreatedAt    time.Time    `db:"created_at" swaggertype:"string" format:"date-time"`
+	Balance      int64        `db:"balance"`
+	RentedBooks  []BookRental `db:"rented_books"`
 }
 
 type BookRental struct {
 	ID         int        `db:"id"`
 	BookID     int        `db:"book_id"`
+	CopyID     int        `db:"copy_id"`
 	UserID     int        `db:"user_id"`
 	RentalDate time.Time  `db:"rental_date"`
+	DueDate    time.Time  `db:"due_date"`
 	ReturnDate *time.Time `db:"return_date"`
+	Renewals   int        `db:"renewals"`
+	Overdue    bool       `db:"overdue"`
 	CreatedAt  time.Time  `db:"created_at" swaggertype:"string" format:"date-time"`
 }
 
+// Loan - выдача вместе с книгой и её автором
+type Loan struct {
+	BookRental
+	Book Book `db:"book"`
+}
+
+// OverdueRental - просроченная аренда с данными книги и читателя для библиотекаря
+type OverdueRental struct {
+	BookRental
+	BookTitle string `db:"book_title"`
+	UserName  string `db:"user_name"`
+	UserEmail string `db:"user_email"`
+}
+
+const (
+	LedgerFine    = "fine"
+	LedgerPayment = "payment"
+	LedgerWaiver  = "waiver"
+)
+
+// LedgerEntry - запись журнала счёта читателя, сумма в копейках
+type LedgerEntry struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	RentalID  *int      `db:"rental_id"`
+	Kind      string    `db:"kind"`
+	Amount    int64     `db:"amount"`
+	Note      string    `db:"note"`
+	CreatedAt time.Time `db:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+const (
+	HoldWaiting   = "waiting"
+	HoldReady     = "ready"
+	HoldFulfilled = "fulfilled"
+	HoldExpired   = "expired"
+	HoldCancelled = "cancelled"
+)
+
+// Hold - бронь выданной книги; очередь броней на книгу обслуживается по порядку создания,
+// готовой брони отложен конкретный экземпляр CopyID
+type Hold struct {
+	ID        int        `db:"id"`
+	BookID    int        `db:"book_id"`
+	CopyID    *int       `db:"copy_id"`
+	UserID    int        `db:"user_id"`
+	Status    string     `db:"status"`
+	ReadyAt   *time.Time `db:"ready_at"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+// Области действия API ключей; ScopeRead разрешает GET запросы во всех группах
+const (
+	ScopeRead    = "read"
+	ScopeRental  = "rental"
+	ScopeCatalog = "catalog"
+	ScopeUsers   = "users"
+)
+
+var APIKeyScopes = []string{ScopeRead, ScopeRental, ScopeCatalog, ScopeUsers}
+
+// APIKey - ключ машинного клиента; в базе хранится только хеш ключа
+type APIKey struct {
+	ID         int
+	Name       string
+	Prefix     string
+	KeyHash    string `json:"-"`
+	Scopes     []string
+	CreatedBy  *int
+	CreatedAt  time.Time  `swaggertype:"string" format:"date-time"`
+	LastUsedAt *time.Time `swaggertype:"string" format:"date-time"`
+	RevokedAt  *time.Time `swaggertype:"string" format:"date-time"`
+}
+
+// PolicyViolation - причина отказа в выдаче по правилам библиотеки
+type PolicyViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 type UniqueBookRental struct {
 	BookID int `db:"book_id"`
 	UserID int `db:"user_id"`
@@ -47,3 +177,87 @@ type AuthorWithRentCount struct {
 	Author    Author
 	RentCount int
 }
+
+// Конфигурации текстового поиска Postgres
+const (
+	SearchEnglish = "english"
+	SearchRussian = "russian"
+)
+
+const (
+	SearchHitBook   = "book"
+	SearchHitAuthor = "author"
+)
+
+// SearchQuery - полнотекстовый запрос; пустой Language выбирается по алфавиту запроса
+type SearchQuery struct {
+	Text     string
+	Language string
+	Limit    int
+}
+
+// SearchHit - найденная книга или автор; Headline - фрагмент с выделенными совпадениями
+type SearchHit struct {
+	Kind       string  `db:"kind"`
+	ID         int     `db:"id"`
+	Title      string  `db:"title"`
+	AuthorID   int     `db:"author_id"`
+	AuthorName string  `db:"author_name"`
+	Rank       float64 `db:"rank"`
+	Headline   string  `db:"headline"`
+}
+
+const DefaultSuggestLimit = 10
+
+// Suggestion - подсказка автодополнения: название книги или имя автора, Score - сходство с введённым текстом
+type Suggestion struct {
+	Kind  string  `db:"kind"`
+	ID    int     `db:"id"`
+	Text  string  `db:"text"`
+	Score float64 `db:"score"`
+}
+
+// ImportRow - строка импорта каталога; Line - номер строки в исходном файле
+type ImportRow struct {
+	Line       int
+	Title      string
+	AuthorName string
+	ISBN       *string
+	Copies     int
+}
+
+const (
+	ImportAccepted = "accepted"
+	ImportRejected = "rejected"
+)
+
+// ImportLine - итог по строке импорта: созданная книга, либо причины отказа
+type ImportLine struct {
+	Line   int
+	Status string
+	BookID int
+	Errors []FieldError
+}
+
+// ImportReport - отчёт об импорте каталога по всем строкам файла
+type ImportReport struct {
+	Accepted       int
+	Rejected       int
+	AuthorsCreated int
+	Lines          []ImportLine
+}
+
+// Наборы данных выгрузки
+const (
+	DatasetBooks   = "books"
+	DatasetAuthors = "authors"
+	DatasetUsers   = "users"
+	DatasetRentals = "rentals"
+)
+
+// Форматы выгрузки; excel - CSV с BOM и CRLF, который Excel открывает в UTF-8
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+	FormatExcel = "excel"
+)
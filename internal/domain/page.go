@@ -0,0 +1,40 @@
+package domain
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Операторы фильтров списков
+const (
+	FilterEq   = "eq"
+	FilterNe   = "ne"
+	FilterLt   = "lt"
+	FilterLte  = "lte"
+	FilterGt   = "gt"
+	FilterGte  = "gte"
+	FilterLike = "like"
+)
+
+// Filter - условие отбора вида поле:оператор:значение; допустимые поля задаёт репозиторий
+type Filter struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// PageRequest - запрос страницы списка; After - курсор из предыдущей страницы
+type PageRequest struct {
+	Limit   int
+	After   string
+	Sort    string
+	Desc    bool
+	Filters []Filter
+}
+
+// Page - страница списка; NextCursor пуст на последней странице, TotalEstimate - оценка планировщика, а не точный счёт
+type Page[T any] struct {
+	Items         []T
+	NextCursor    string
+	TotalEstimate int64
+}
@@ -0,0 +1,78 @@
+package facade
+
+import (
+	"context"
+	"errors"
+	"library/internal/domain"
+)
+
+// AddCopy добавляет экземпляр книги; если книгу ждут по брони, экземпляр сразу откладывается первому в очереди
+func (l LibraryFacade) AddCopy(ctx context.Context, c *domain.Copy) error {
+	return l.tx.Do(ctx, func(ctx context.Context) error {
+		_, err := l.book.GetBookForUpdate(ctx, c.BookID)
+		if err != nil {
+			return err
+		}
+
+		c.Available = false
+		err = l.copies.AddCopy(ctx, c)
+		if err != nil {
+			return err
+		}
+
+		err = l.releaseShelf(ctx, c.BookID, c.ID)
+		if err != nil {
+			return err
+		}
+
+		added, err := l.copies.GetCopy(ctx, c.ID)
+		if err != nil {
+			return err
+		}
+		*c = *added
+		return nil
+	})
+}
+
+func (l LibraryFacade) ListCopies(ctx context.Context, bookID int) ([]domain.Copy, error) {
+	_, err := l.book.GetBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	return l.copies.ListCopies(ctx, bookID)
+}
+
+// RetireCopy списывает экземпляр; выданный, отложенный по брони или уже списанный экземпляр списать нельзя
+func (l LibraryFacade) RetireCopy(ctx context.Context, copyID int) error {
+	return l.tx.Do(ctx, func(ctx context.Context) error {
+		c, err := l.copies.GetCopy(ctx, copyID)
+		if err != nil {
+			return err
+		}
+		_, err = l.book.GetBookForUpdate(ctx, c.BookID)
+		if err != nil {
+			return err
+		}
+		if c.RetiredAt != nil {
+			return &domain.ErrCopyInUse{CopyID: copyID, Reason: "copy already retired"}
+		}
+
+		_, err = l.rental.GetCopyRental(ctx, copyID)
+		if err == nil {
+			return &domain.ErrCopyInUse{CopyID: copyID, Reason: "copy is on loan"}
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+
+		pickup, err := l.hold.GetCopyHold(ctx, copyID)
+		if err != nil {
+			return err
+		}
+		if pickup != nil {
+			return &domain.ErrCopyInUse{CopyID: copyID, Reason: "copy is on the hold shelf"}
+		}
+
+		return l.copies.RetireCopy(ctx, copyID)
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"library/config"
 	"library/internal/domain"
+	"library/internal/policy"
 	"library/internal/repository"
 	"library/internal/usecase"
 	"time"
@@ -16,82 +18,291 @@ import (
 
 type Facader interface {
 	RentBook(ctx context.Context, bookID, userID int) error
-	ReturnBook(ctx context.Context, bookID int) error
+	ReturnBook(ctx context.Context, bookID, userID int) error
+	ReturnCopy(ctx context.Context, barcode string) error
+	RenewRental(ctx context.Context, rentalID int) (*domain.BookRental, error)
+	GetRental(ctx context.Context, rentalID int) (*domain.BookRental, error)
+	GetCopyRental(ctx context.Context, barcode string) (*domain.BookRental, error)
+	ListOverdue(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error)
+	ListUserOverdue(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error)
+	ListLoans(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.Loan], error)
+	ListLoanHistory(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.Loan], error)
+	AddCopy(ctx context.Context, c *domain.Copy) error
+	ListCopies(ctx context.Context, bookID int) ([]domain.Copy, error)
+	RetireCopy(ctx context.Context, copyID int) error
+	PlaceHold(ctx context.Context, bookID, userID int) (*domain.Hold, error)
+	CancelHold(ctx context.Context, holdID int) error
+	GetHold(ctx context.Context, holdID int) (*domain.Hold, error)
+	ListBookHolds(ctx context.Context, bookID int) ([]domain.Hold, error)
+	ListUserHolds(ctx context.Context, userID int) ([]domain.Hold, error)
+	ExpireHolds(ctx context.Context) error
 	InitializeDataIfEmpty(ctx context.Context) error
+	EnsureLibrarian(ctx context.Context, email, password string) error
 }
 
 type LibraryFacade struct {
 	db     *sqlx.DB
+	tx     repository.TxManager
+	conf   *config.LibraryConfig
 	author usecase.Authorer
 	book   usecase.Booker
+	copies usecase.Copier
 	rental usecase.Rentaler
 	user   usecase.Userer
+	ledger usecase.Ledgerer
+	hold   usecase.Holder
+	policy policy.Evaluator
 }
 
 func NewLibraryFacade(
 	db *sqlx.DB,
+	tx repository.TxManager,
+	conf *config.LibraryConfig,
 	author usecase.Authorer,
 	book usecase.Booker,
+	copies usecase.Copier,
 	rental usecase.Rentaler,
 	user usecase.Userer,
+	ledger usecase.Ledgerer,
+	hold usecase.Holder,
+	policy policy.Evaluator,
 ) *LibraryFacade {
 	return &LibraryFacade{
 		db:     db,
+		tx:     tx,
+		conf:   conf,
 		author: author,
 		book:   book,
+		copies: copies,
 		rental: rental,
 		user:   user,
+		ledger: ledger,
+		hold:   hold,
+		policy: policy,
 	}
 }
 
+// RentBook выдаёт читателю экземпляр книги: отложенный для него по брони, либо свободный экземпляр с полки
 func (l LibraryFacade) RentBook(ctx context.Context, bookID, userID int) error {
-	book, err := l.book.GetBook(ctx, bookID)
+	return l.tx.Do(ctx, func(ctx context.Context) error {
+		book, err := l.book.GetBookForUpdate(ctx, bookID)
+		if err != nil {
+			return err
+		}
+
+		pickup, err := l.hold.GetReadyHold(ctx, bookID, userID)
+		if err != nil {
+			return err
+		}
+		var copyID int
+		if pickup != nil && pickup.CopyID != nil {
+			copyID = *pickup.CopyID
+		} else {
+			shelf, err := l.copies.FirstAvailable(ctx, bookID)
+			if err != nil {
+				return err
+			}
+			if shelf == nil {
+				return &domain.ErrBookNotAvailable{BookID: bookID}
+			}
+			copyID = shelf.ID
+		}
+
+		user, err := l.user.GetByIDUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		loans, err := l.rental.ListActiveBooks(ctx, userID)
+		if err != nil {
+			return err
+		}
+		violations := l.policy.Evaluate(policy.Checkout{User: user, Book: book, Loans: loans, Now: time.Now()})
+		if len(violations) > 0 {
+			return &domain.ErrPolicyDenied{BookID: bookID, UserID: userID, Violations: violations}
+		}
+
+		balance, err := l.ledger.GetBalance(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if balance > l.conf.MaxBalance {
+			return &domain.ErrBalanceExceeded{UserID: userID, Balance: balance, Limit: l.conf.MaxBalance}
+		}
+
+		if pickup != nil {
+			err = l.hold.SetStatus(ctx, pickup, domain.HoldFulfilled)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = l.rental.RentBook(ctx, bookID, copyID, userID, time.Now().Add(l.loanPeriod(book)))
+		if err != nil {
+			return err
+		}
+
+		return l.copies.SetAvailable(ctx, copyID, false)
+	})
+}
+
+// ReturnBook возвращает экземпляр книги, который на руках у читателя
+func (l LibraryFacade) ReturnBook(ctx context.Context, bookID, userID int) error {
+	return l.tx.Do(ctx, func(ctx context.Context) error {
+		rental, err := l.rental.GetUserRental(ctx, bookID, userID)
+		if err != nil {
+			return err
+		}
+		return l.returnCopy(ctx, bookID, rental.CopyID)
+	})
+}
+
+// ReturnCopy принимает экземпляр по штрихкоду
+func (l LibraryFacade) ReturnCopy(ctx context.Context, barcode string) error {
+	return l.tx.Do(ctx, func(ctx context.Context) error {
+		c, err := l.copies.GetCopyByBarcode(ctx, barcode)
+		if err != nil {
+			return err
+		}
+		return l.returnCopy(ctx, c.BookID, c.ID)
+	})
+}
+
+// returnCopy закрывает выдачу экземпляра, начисляет штраф за просрочку и передаёт экземпляр очереди броней
+func (l LibraryFacade) returnCopy(ctx context.Context, bookID, copyID int) error {
+	_, err := l.book.GetBookForUpdate(ctx, bookID)
 	if err != nil {
 		return err
 	}
-	if !book.Available {
-		return fmt.Errorf("book is not available")
-	}
 
-	_, err = l.user.GetByIDUser(ctx, userID)
+	rental, err := l.rental.ReturnCopy(ctx, copyID)
 	if err != nil {
 		return err
 	}
 
-	err = l.rental.RentBook(ctx, bookID, userID)
-	if err != nil {
+	if fine := l.lateFine(rental); fine > 0 {
+		note := fmt.Sprintf("late return of book %d", bookID)
+		err = l.ledger.ChargeFine(ctx, rental.UserID, rental.ID, fine, note)
+		if err != nil {
+			return err
+		}
+	}
+
+	return l.releaseShelf(ctx, bookID, copyID)
+}
+
+// RenewRental продлевает выдачу на срок выдачи книги, если её никто не ждёт
+func (l LibraryFacade) RenewRental(ctx context.Context, rentalID int) (*domain.BookRental, error) {
+	var renewed *domain.BookRental
+	err := l.tx.Do(ctx, func(ctx context.Context) error {
+		rental, err := l.rental.GetRental(ctx, rentalID)
+		if err != nil {
+			return err
+		}
+		// книга блокируется до проверки броней: PlaceHold берёт ту же блокировку и не разойдётся с продлением
+		book, err := l.book.GetBookForUpdate(ctx, rental.BookID)
+		if err != nil {
+			return err
+		}
+		if rental.ReturnDate != nil {
+			return &domain.ErrRenewalNotAllowed{RentalID: rentalID, Reason: "book already returned"}
+		}
+		if rental.Overdue {
+			return &domain.ErrRenewalNotAllowed{RentalID: rentalID, Reason: "rental is overdue"}
+		}
+		if rental.Renewals >= l.conf.MaxRenewals {
+			return &domain.ErrRenewalNotAllowed{RentalID: rentalID, Reason: "renewal limit reached"}
+		}
+
+		held, err := l.hold.HasWaitingHolds(ctx, rental.BookID)
+		if err != nil {
+			return err
+		}
+		if held {
+			return &domain.ErrRenewalNotAllowed{RentalID: rentalID, Reason: "book is on hold for another patron"}
+		}
+
+		renewed, err = l.rental.RenewRental(ctx, rentalID, rental.DueDate.Add(l.loanPeriod(book)))
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	book.Available = false
-	err = l.book.UpdateBook(ctx, book)
+	return renewed, nil
+}
+
+func (l LibraryFacade) GetRental(ctx context.Context, rentalID int) (*domain.BookRental, error) {
+	return l.rental.GetRental(ctx, rentalID)
+}
+
+func (l LibraryFacade) GetCopyRental(ctx context.Context, barcode string) (*domain.BookRental, error) {
+	c, err := l.copies.GetCopyByBarcode(ctx, barcode)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return l.rental.GetCopyRental(ctx, c.ID)
+}
 
-	return nil
+func (l LibraryFacade) ListOverdue(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error) {
+	return l.rental.ListOverdue(ctx, req)
 }
-func (l LibraryFacade) ReturnBook(ctx context.Context, bookID int) error {
-	book, err := l.book.GetBook(ctx, bookID)
+
+func (l LibraryFacade) ListUserOverdue(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error) {
+	_, err := l.user.GetByIDUser(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if book.Available {
-		return fmt.Errorf("book was not issued")
+	return l.rental.ListOverdueByUser(ctx, userID, req)
+}
+
+func (l LibraryFacade) ListLoans(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.Loan], error) {
+	return l.rental.ListLoans(ctx, userID, req)
+}
+
+func (l LibraryFacade) ListLoanHistory(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.Loan], error) {
+	return l.rental.ListLoanHistory(ctx, userID, req)
+}
+
+// loanPeriod - срок выдачи книги: собственный срок книги, либо срок библиотеки по умолчанию
+func (l LibraryFacade) loanPeriod(book *domain.Book) time.Duration {
+	if book.LoanPeriodDays != nil {
+		return time.Duration(*book.LoanPeriodDays) * 24 * time.Hour
 	}
+	return l.conf.LoanPeriod
+}
 
-	err = l.rental.ReturnBook(ctx, bookID)
-	if err != nil {
-		return err
+// lateFine - штраф за каждый начатый день просрочки
+func (l LibraryFacade) lateFine(rental *domain.BookRental) int64 {
+	if rental.ReturnDate == nil || !rental.ReturnDate.After(rental.DueDate) {
+		return 0
 	}
+	late := rental.ReturnDate.Sub(rental.DueDate)
+	days := int64((late + 24*time.Hour - 1) / (24 * time.Hour))
+	return days * l.conf.FinePerDay
+}
 
-	book.Available = true
-	err = l.book.UpdateBook(ctx, book)
-	if err != nil {
+// EnsureLibrarian создаёт учётную запись библиотекаря, если её ещё нет
+func (l LibraryFacade) EnsureLibrarian(ctx context.Context, email, password string) error {
+	if email == "" || password == "" {
+		return nil
+	}
+
+	_, err := l.user.GetByEmailUser(ctx, email)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
 		return err
 	}
 
-	return nil
+	return l.user.CreateUser(ctx, &domain.User{
+		Name:      "Librarian",
+		Email:     email,
+		Role:      domain.RoleLibrarian,
+		Password:  password,
+		CreatedAt: time.Now(),
+	})
 }
 
 func (lf LibraryFacade) InitializeDataIfEmpty(ctx context.Context) error {
@@ -130,9 +341,11 @@ func (lf LibraryFacade) InitializeDataIfEmpty(ctx context.Context) error {
 	if !ok {
 		users := make([]domain.User, 55) // Больше 50
 		for i := 0; i < 55; i++ {
+			birthDate := gofakeit.DateRange(time.Now().AddDate(-80, 0, 0), time.Now().AddDate(-10, 0, 0))
 			users[i] = domain.User{
 				Name:      gofakeit.Name(),
 				Email:     gofakeit.Email(),
+				BirthDate: &birthDate,
 				CreatedAt: time.Now(),
 			}
 			err := lf.user.CreateUser(ctx, &users[i])
@@ -149,11 +362,12 @@ func (lf LibraryFacade) InitializeDataIfEmpty(ctx context.Context) error {
 	}
 
 	if !ok {
-		authors, err := lf.author.ListAuthors(ctx)
+		page, err := lf.author.ListAuthors(ctx, domain.PageRequest{Limit: domain.MaxPageLimit})
 		if err != nil {
 			return err
 		}
 
+		authors := page.Items
 		if len(authors) == 0 {
 			return errors.New("no authors found to assign books")
 		}
@@ -164,13 +378,21 @@ func (lf LibraryFacade) InitializeDataIfEmpty(ctx context.Context) error {
 			books[i] = domain.Book{
 				Title:     gofakeit.BookTitle(),
 				AuthorID:  author.ID,
+				Genre:     gofakeit.Book().Genre,
 				CreatedAt: time.Now(),
-				Available: true,
 			}
 			err := lf.book.AddBook(ctx, &books[i])
 			if err != nil {
 				return err
 			}
+			err = lf.copies.AddCopy(ctx, &domain.Copy{
+				BookID:    books[i].ID,
+				Barcode:   fmt.Sprintf("DEMO-%06d", books[i].ID),
+				Available: true,
+			})
+			if err != nil {
+				return err
+			}
 		}
 
 	}
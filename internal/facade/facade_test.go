@@ -0,0 +1,224 @@
+package facade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/config"
+	"library/internal/domain"
+	"library/internal/policy"
+	"library/internal/repository"
+	"library/internal/testdb"
+	"library/internal/usecase"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func newTestFacade(db *sqlx.DB) *LibraryFacade {
+	authorRepo := repository.NewAuthorRepository(db)
+	txManager := repository.NewTxManager(db)
+	conf := &config.LibraryConfig{LoanPeriod: 14 * 24 * time.Hour, FinePerDay: 10, MaxBalance: 500, HoldPickupWindow: 72 * time.Hour, MaxRenewals: 2}
+	return NewLibraryFacade(db, txManager, conf,
+		usecase.NewAuthorUseCase(authorRepo),
+		usecase.NewBookUseCase(repository.NewBookRepository(db), authorRepo),
+		usecase.NewCopyUseCase(repository.NewCopyRepository(db)),
+		usecase.NewRentUseCase(repository.NewRentalRepository(db)),
+		usecase.NewUserUseCase(repository.NewUserRepository(db)),
+		usecase.NewLedgerUseCase(repository.NewLedgerRepository(db), txManager),
+		usecase.NewHoldUseCase(repository.NewHoldRepository(db)),
+		policy.NewEngineFromConfig(&policy.Config{}),
+	)
+}
+
+// TestRentBookConcurrent - сотни читателей одновременно берут единственный экземпляр книги:
+// выдача проходит ровно одна, остальные получают ErrBookNotAvailable
+func TestRentBookConcurrent(t *testing.T) {
+	const readers = 200
+
+	db := testdb.Open(t)
+	// соединений меньше, чем читателей, чтобы не упереться в max_connections сервера
+	db.SetMaxOpenConns(20)
+	ctx := context.Background()
+	f := newTestFacade(db)
+
+	author := &domain.Author{Name: "Лев Толстой"}
+	if err := repository.NewAuthorRepository(db).Create(ctx, author); err != nil {
+		t.Fatal(err)
+	}
+	book := &domain.Book{Title: "Война и мир", AuthorID: author.ID}
+	if err := repository.NewBookRepository(db).Create(ctx, book); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.NewCopyRepository(db).Create(ctx, &domain.Copy{BookID: book.ID, Barcode: "STRESS-1", Available: true}); err != nil {
+		t.Fatal(err)
+	}
+	userRepo := repository.NewUserRepository(db)
+	userIDs := make([]int, readers)
+	for i := range userIDs {
+		user := &domain.User{Name: fmt.Sprintf("Reader %d", i), Email: fmt.Sprintf("reader%d@example.com", i), Role: domain.RolePatron}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		userIDs[i] = user.ID
+	}
+
+	start := make(chan struct{})
+	errs := make([]error, readers)
+	var wg sync.WaitGroup
+	for i, userID := range userIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs[i] = f.RentBook(ctx, book.ID, userID)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for i, err := range errs {
+		var notAvailable *domain.ErrBookNotAvailable
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.As(err, &notAvailable), errors.Is(err, domain.ErrConflict):
+		default:
+			t.Errorf("reader %d: unexpected error %v", i, err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d rentals succeeded, want exactly 1", succeeded)
+	}
+
+	var active int
+	err := db.GetContext(ctx, &active, `SELECT COUNT(*) FROM book_rental WHERE book_id = $1 AND return_date IS NULL`, book.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active != 1 {
+		t.Fatalf("%d active rentals for the copy, want 1", active)
+	}
+}
+
+func TestLoanPeriod(t *testing.T) {
+	f := LibraryFacade{conf: &config.LibraryConfig{LoanPeriod: 14 * 24 * time.Hour}}
+	days := 3
+	if got := f.loanPeriod(&domain.Book{}); got != 14*24*time.Hour {
+		t.Errorf("default loan period = %v, want 336h", got)
+	}
+	if got := f.loanPeriod(&domain.Book{LoanPeriodDays: &days}); got != 3*24*time.Hour {
+		t.Errorf("book loan period = %v, want 72h", got)
+	}
+}
+
+func TestLateFine(t *testing.T) {
+	f := LibraryFacade{conf: &config.LibraryConfig{FinePerDay: 10}}
+	due := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		returned *time.Time
+		want     int64
+	}{
+		{"not returned", nil, 0},
+		{"returned early", ptr(due.Add(-time.Hour)), 0},
+		{"returned on the due moment", ptr(due), 0},
+		{"one minute late", ptr(due.Add(time.Minute)), 10},
+		{"exactly one day late", ptr(due.Add(24 * time.Hour)), 10},
+		{"one day and a second late", ptr(due.Add(24*time.Hour + time.Second)), 20},
+		{"ten days late", ptr(due.Add(10 * 24 * time.Hour)), 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rental := &domain.BookRental{DueDate: due, ReturnDate: tt.returned}
+			if got := f.lateFine(rental); got != tt.want {
+				t.Errorf("lateFine() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestListOverdue - просроченной считается только невозвращённая выдача с прошедшим сроком
+func TestListOverdue(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	f := newTestFacade(db)
+
+	seed := []string{
+		`INSERT INTO authors (name) VALUES ('Author')`,
+		`INSERT INTO books (title, author_id) SELECT 'Book ' || i, 1 FROM generate_series(1, 3) i`,
+		`INSERT INTO book_copies (book_id, barcode, available) SELECT i, 'OVERDUE-' || i, FALSE FROM generate_series(1, 3) i`,
+		`INSERT INTO users (name, email) VALUES ('Reader', 'reader@example.com')`,
+		// просрочена; ещё не просрочена; была просрочена, но уже возвращена
+		`INSERT INTO book_rental (book_id, copy_id, user_id, rental_date, due_date, return_date) VALUES
+			(1, 1, 1, now() - interval '20 days', now() - interval '6 days', NULL),
+			(2, 2, 1, now() - interval '1 day', now() + interval '13 days', NULL),
+			(3, 3, 1, now() - interval '20 days', now() - interval '6 days', now() - interval '1 day')`,
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, list := range map[string]func() (*domain.Page[domain.OverdueRental], error){
+		"all": func() (*domain.Page[domain.OverdueRental], error) { return f.ListOverdue(ctx, domain.PageRequest{}) },
+		"user": func() (*domain.Page[domain.OverdueRental], error) {
+			return f.ListUserOverdue(ctx, 1, domain.PageRequest{})
+		},
+	} {
+		page, err := list()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(page.Items) != 1 || page.Items[0].BookID != 1 || !page.Items[0].Overdue {
+			t.Fatalf("%s: overdue rentals %+v, want only the rental of book 1", name, page.Items)
+		}
+	}
+
+	for id, want := range map[int]bool{1: true, 2: false, 3: false} {
+		rental, err := f.GetRental(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rental.Overdue != want {
+			t.Errorf("rental %d: overdue = %v, want %v", id, rental.Overdue, want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// TestRetireCopyTwice - повторное списание экземпляра - конфликт, а не «экземпляр не найден»
+func TestRetireCopyTwice(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	f := newTestFacade(db)
+
+	seed := []string{
+		`INSERT INTO authors (name) VALUES ('Author')`,
+		`INSERT INTO books (title, author_id) VALUES ('Book', 1)`,
+		`INSERT INTO book_copies (book_id, barcode) VALUES (1, 'RETIRE-1')`,
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := f.RetireCopy(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	var inUse *domain.ErrCopyInUse
+	if err := f.RetireCopy(ctx, 1); !errors.As(err, &inUse) || !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("second RetireCopy() = %v, want ErrCopyInUse", err)
+	}
+	if err := f.RetireCopy(ctx, 2); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("RetireCopy() of a missing copy = %v, want not found", err)
+	}
+}
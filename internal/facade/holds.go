@@ -0,0 +1,130 @@
+package facade
+
+import (
+	"context"
+	"errors"
+	"library/internal/domain"
+	"time"
+)
+
+func (l LibraryFacade) PlaceHold(ctx context.Context, bookID, userID int) (*domain.Hold, error) {
+	var hold *domain.Hold
+	err := l.tx.Do(ctx, func(ctx context.Context) error {
+		book, err := l.book.GetBookForUpdate(ctx, bookID)
+		if err != nil {
+			return err
+		}
+		if book.Available {
+			return &domain.ErrHoldNotAllowed{BookID: bookID, Reason: "book is available"}
+		}
+
+		_, err = l.user.GetByIDUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = l.rental.GetUserRental(ctx, bookID, userID)
+		if err == nil {
+			return &domain.ErrHoldNotAllowed{BookID: bookID, Reason: "user already has this book"}
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+
+		hold, err = l.hold.PlaceHold(ctx, bookID, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (l LibraryFacade) CancelHold(ctx context.Context, holdID int) error {
+	return l.tx.Do(ctx, func(ctx context.Context) error {
+		hold, err := l.hold.GetHold(ctx, holdID)
+		if err != nil {
+			return err
+		}
+		if hold.Status != domain.HoldWaiting && hold.Status != domain.HoldReady {
+			return &domain.ErrHoldNotAllowed{BookID: hold.BookID, Reason: "hold is no longer active"}
+		}
+
+		wasReady := hold.Status == domain.HoldReady
+		err = l.hold.SetStatus(ctx, hold, domain.HoldCancelled)
+		if err != nil {
+			return err
+		}
+		if !wasReady || hold.CopyID == nil {
+			return nil
+		}
+
+		return l.releaseShelf(ctx, hold.BookID, *hold.CopyID)
+	})
+}
+
+func (l LibraryFacade) GetHold(ctx context.Context, holdID int) (*domain.Hold, error) {
+	return l.hold.GetHold(ctx, holdID)
+}
+
+func (l LibraryFacade) ListBookHolds(ctx context.Context, bookID int) ([]domain.Hold, error) {
+	return l.hold.ListBookHolds(ctx, bookID)
+}
+
+func (l LibraryFacade) ListUserHolds(ctx context.Context, userID int) ([]domain.Hold, error) {
+	return l.hold.ListUserHolds(ctx, userID)
+}
+
+// ExpireHolds снимает брони, которые не забрали за отведённое время, и передаёт экземпляр следующему в очереди
+func (l LibraryFacade) ExpireHolds(ctx context.Context) error {
+	expired, err := l.hold.ListExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, h := range expired {
+		err := l.tx.Do(ctx, func(ctx context.Context) error {
+			hold, err := l.hold.GetHold(ctx, h.ID)
+			if err != nil {
+				return err
+			}
+			if hold.Status != domain.HoldReady || hold.ExpiresAt == nil || hold.ExpiresAt.After(time.Now()) {
+				return nil
+			}
+
+			err = l.hold.SetStatus(ctx, hold, domain.HoldExpired)
+			if err != nil {
+				return err
+			}
+			if hold.CopyID == nil {
+				return nil
+			}
+
+			return l.releaseShelf(ctx, hold.BookID, *hold.CopyID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseShelf передаёт освободившийся экземпляр следующему в очереди, либо возвращает его на полку
+func (l LibraryFacade) releaseShelf(ctx context.Context, bookID, copyID int) error {
+	_, err := l.book.GetBookForUpdate(ctx, bookID)
+	if err != nil {
+		return err
+	}
+
+	next, err := l.hold.PromoteNext(ctx, bookID, copyID, l.conf.HoldPickupWindow)
+	if err != nil {
+		return err
+	}
+	if next != nil {
+		return l.copies.SetAvailable(ctx, copyID, false)
+	}
+
+	return l.copies.SetAvailable(ctx, copyID, true)
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"library/internal/auth"
+	"library/internal/facade"
+	"library/internal/usecase"
+	"library/responder"
+	"net/http"
+)
+
+type Accounter interface {
+	Profile(w http.ResponseWriter, r *http.Request)
+	Loans(w http.ResponseWriter, r *http.Request)
+	History(w http.ResponseWriter, r *http.Request)
+	Holds(w http.ResponseWriter, r *http.Request)
+	Checkout(w http.ResponseWriter, r *http.Request)
+	Return(w http.ResponseWriter, r *http.Request)
+}
+
+// AccountHandler - операции читателя над собственным счётом; пользователь берётся из токена
+type AccountHandler struct {
+	userUC    usecase.Userer
+	library   facade.Facader
+	responder responder.Responder
+}
+
+func NewAccountHandler(userUC usecase.Userer, library facade.Facader, responder responder.Responder) Accounter {
+	return &AccountHandler{
+		userUC:    userUC,
+		library:   library,
+		responder: responder,
+	}
+}
+
+// @Summary			my profile
+// @Description		profile of the authenticated user with active rentals and balance
+// @Tags			me
+// @Accept			json
+// @Produce			json
+// @Success			200		{object}	Response{data=UserResponse}
+// @Security		ApiKeyAuth
+// @Router			/me [get]
+func (h *AccountHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.me(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.userUC.GetByIDUser(r.Context(), userID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newUserResponse(user),
+	})
+}
+
+// @Summary			my loans
+// @Description		books currently on loan with book and author details
+// @Tags			me
+// @Accept			json
+// @Produce			json
+// @Param			limit   query	int	false  "page size, 1-100, default 20"
+// @Param			after   query	string	false  "next_cursor of the previous page"
+// @Param			sort   query	string	false  "id, rental_date (default, newest first), due_date, prefix - for descending"
+// @Param			filter   query	[]string	false  "field:op:value on book_id, author_id, genre, rental_date, due_date; ops eq, ne, lt, lte, gt, gte, like"	collectionFormat(multi)
+// @Success			200		{object}	Response{data=PageResponse{items=[]LoanResponse}}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/me/loans [get]
+func (h *AccountHandler) Loans(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.me(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := pageRequest(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	loans, err := h.library.ListLoans(r.Context(), userID, req)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newPageResponse(loans, newLoanResponses),
+	})
+}
+
+// @Summary			my loan history
+// @Description		all loans including returned ones, newest first
+// @Tags			me
+// @Accept			json
+// @Produce			json
+// @Param			limit   query	int	false  "page size, 1-100, default 20"
+// @Param			after   query	string	false  "next_cursor of the previous page"
+// @Param			sort   query	string	false  "id, rental_date (default, newest first), due_date, prefix - for descending"
+// @Param			filter   query	[]string	false  "field:op:value on book_id, author_id, genre, rental_date, due_date; ops eq, ne, lt, lte, gt, gte, like"	collectionFormat(multi)
+// @Success			200		{object}	Response{data=PageResponse{items=[]LoanResponse}}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/me/history [get]
+func (h *AccountHandler) History(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.me(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := pageRequest(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	loans, err := h.library.ListLoanHistory(r.Context(), userID, req)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newPageResponse(loans, newLoanResponses),
+	})
+}
+
+// @Summary			my holds
+// @Description		active holds of the authenticated user
+// @Tags			me
+// @Accept			json
+// @Produce			json
+// @Success			200		{object}	Response{data=[]HoldResponse}
+// @Security		ApiKeyAuth
+// @Router			/me/holds [get]
+func (h *AccountHandler) Holds(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.me(w, r)
+	if !ok {
+		return
+	}
+
+	holds, err := h.library.ListUserHolds(r.Context(), userID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newHoldResponses(holds),
+	})
+}
+
+// @Summary			checkout book
+// @Description		rent a book for the authenticated user
+// @Tags			me
+// @Accept			json
+// @Produce			json
+// @Param			bookId   path	string	true  "bookId"
+// @Success			200		{object}	Response{data=Data}
+// @Failure			403		{object}	responder.Problem
+// @Failure			409		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/me/loans/{bookId} [post]
+func (h *AccountHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.me(w, r)
+	if !ok {
+		return
+	}
+
+	bookID, err := pathID(r, "bookId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if err := h.library.RentBook(r.Context(), bookID, userID); err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data: Data{
+			Message: "book has been leased",
+		},
+	})
+}
+
+// @Summary			return book
+// @Description		return a book loaned to the authenticated user
+// @Tags			me
+// @Accept			json
+// @Produce			json
+// @Param			bookId   path	string	true  "bookId"
+// @Success			200		{object}	Response{data=Data}
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/me/loans/{bookId} [delete]
+func (h *AccountHandler) Return(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.me(w, r)
+	if !ok {
+		return
+	}
+
+	bookID, err := pathID(r, "bookId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if err := h.library.ReturnBook(r.Context(), bookID, userID); err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data: Data{
+			Message: "book rental completed",
+		},
+	})
+}
+
+func (h *AccountHandler) me(w http.ResponseWriter, r *http.Request) (int, bool) {
+	principal, ok := auth.PrincipalFrom(r.Context())
+	if !ok {
+		h.responder.ErrorUnauthorized(w, r, auth.ErrMissingToken)
+		return 0, false
+	}
+	return principal.UserID, true
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"library/internal/auth"
+	"library/internal/usecase"
+	"library/responder"
+	"net/http"
+	"strings"
+)
+
+type APIKeyer interface {
+	CreateKey(w http.ResponseWriter, r *http.Request)
+	ListKeys(w http.ResponseWriter, r *http.Request)
+	RevokeKey(w http.ResponseWriter, r *http.Request)
+}
+
+type APIKeyHandler struct {
+	keyUC     usecase.APIKeyer
+	responder responder.Responder
+}
+
+func NewAPIKeyHandler(keyUC usecase.APIKeyer, responder responder.Responder) APIKeyer {
+	return &APIKeyHandler{
+		keyUC:     keyUC,
+		responder: responder,
+	}
+}
+
+// @Summary			create api key
+// @Description		issue an api key for a machine client; scopes: read, rental, catalog, users
+// @Tags			apikey
+// @Accept			x-www-form-urlencoded
+// @Produce			json
+// @Param name   	formData	string	true  "name"
+// @Param scopes   	formData	string	true  "comma separated scopes"
+// @Success			200		{object}	Response{data=IssuedAPIKeyResponse}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/apikey [post]
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		h.responder.ErrorBadRequest(w, r, invalidField("name", "is required"))
+		return
+	}
+
+	var scopes []string
+	for _, scope := range strings.Split(r.FormValue("scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	var createdBy int
+	if principal, ok := auth.PrincipalFrom(r.Context()); ok {
+		createdBy = principal.UserID
+	}
+
+	key, plain, err := h.keyUC.CreateKey(r.Context(), name, scopes, createdBy)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    IssuedAPIKeyResponse{APIKeyResponse: newAPIKeyResponse(key), Key: plain},
+	})
+}
+
+// @Summary			list api keys
+// @Description		list api keys with their scopes and last use
+// @Tags			apikey
+// @Accept			json
+// @Produce			json
+// @Param			limit   query	int	false  "page size, 1-100, default 20"
+// @Param			after   query	string	false  "next_cursor of the previous page"
+// @Param			sort   query	string	false  "id, name, created_at, prefix - for descending"
+// @Param			filter   query	[]string	false  "field:op:value on name, created_by, created_at; ops eq, ne, lt, lte, gt, gte, like"	collectionFormat(multi)
+// @Success			200		{object}	Response{data=PageResponse{items=[]APIKeyResponse}}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/apikey [get]
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	req, err := pageRequest(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	keys, err := h.keyUC.ListKeys(r.Context(), req)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newPageResponse(keys, newAPIKeyResponses),
+	})
+}
+
+// @Summary			revoke api key
+// @Description		revoke api key
+// @Tags			apikey
+// @Accept			json
+// @Produce			json
+// @Param			keyId   path	string	true  "keyId"
+// @Success			200		{object}	Response{data=Data}
+// @Security		ApiKeyAuth
+// @Router			/apikey/{keyId} [delete]
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := pathID(r, "keyId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if err := h.keyUC.RevokeKey(r.Context(), keyID); err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data: Data{
+			Message: "api key has been revoked",
+		},
+	})
+}
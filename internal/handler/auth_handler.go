@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"library/internal/auth"
+	"library/internal/domain"
+	"library/internal/usecase"
+	"library/responder"
+	"net/http"
+)
+
+type Authenticator interface {
+	Login(w http.ResponseWriter, r *http.Request)
+	Refresh(w http.ResponseWriter, r *http.Request)
+}
+
+type AuthHandler struct {
+	authUC    usecase.Authenticator
+	responder responder.Responder
+}
+
+func NewAuthHandler(authUC usecase.Authenticator, responder responder.Responder) Authenticator {
+	return &AuthHandler{
+		authUC:    authUC,
+		responder: responder,
+	}
+}
+
+// @Summary			login
+// @Description		exchange email and password for access and refresh tokens
+// @Tags			auth
+// @Accept			x-www-form-urlencoded
+// @Produce			json
+// @Param email   	formData	string	true  "email"
+// @Param password   	formData	string	true  "password"
+// @Success			200		{object}	Response{data=TokenResponse}
+// @Failure			401		{object}	responder.Problem
+// @Router			/auth/login [post]
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.authUC.Login(r.Context(), r.FormValue("email"), r.FormValue("password"))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newTokenResponse(tokens),
+	})
+}
+
+// @Summary			refresh tokens
+// @Description		exchange refresh token for a new token pair
+// @Tags			auth
+// @Accept			x-www-form-urlencoded
+// @Produce			json
+// @Param refresh_token   	formData	string	true  "refresh token"
+// @Success			200		{object}	Response{data=TokenResponse}
+// @Failure			401		{object}	responder.Problem
+// @Router			/auth/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.authUC.Refresh(r.Context(), r.FormValue("refresh_token"))
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newTokenResponse(tokens),
+	})
+}
+
+func (h *AuthHandler) error(w http.ResponseWriter, r *http.Request, err error) {
+	var invalid *domain.ErrInvalidCredentials
+	if errors.As(err, &invalid) || errors.Is(err, auth.ErrInvalidToken) {
+		h.responder.ErrorUnauthorized(w, r, err)
+		return
+	}
+	h.responder.Error(w, r, err)
+}
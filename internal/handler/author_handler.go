@@ -14,6 +14,8 @@ type Authorer interface {
 	GetAuthor(w http.ResponseWriter, r *http.Request)
 	GetTopAuthors(w http.ResponseWriter, r *http.Request)
 	GetAllAuthors(w http.ResponseWriter, r *http.Request)
+	UpdateAuthor(w http.ResponseWriter, r *http.Request)
+	PatchAuthor(w http.ResponseWriter, r *http.Request)
 	DeleteAuthor(w http.ResponseWriter, r *http.Request)
 	GetByBooksAuthor(w http.ResponseWriter, r *http.Request)
 }
@@ -35,23 +37,27 @@ func NewAuthorHandler(authorUC usecase.Authorer, responder responder.Responder)
 // @Tags			author
 // @Accept			json
 // @Produce			json
-// @Param			author   body	domain.Author	true  "author"
-// @Success			200		{object}	Response
+// @Param			author   body	CreateAuthorRequest	true  "author"
+// @Success			200		{object}	Response{data=AuthorResponse}
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/author [post]
 func (h *AuthorHandler) CreateAuthor(w http.ResponseWriter, r *http.Request) {
-	var author domain.Author
-	if err := json.NewDecoder(r.Body).Decode(&author); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var req CreateAuthorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
 		return
 	}
 
+	author := req.toDomain()
+
 	if err := h.authorUC.CreateAuthor(r.Context(), &author); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.responder.Error(w, r, err)
 		return
 	}
 	h.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    author,
+		Data:    newAuthorResponse(&author),
 	})
 }
 
@@ -61,24 +67,26 @@ func (h *AuthorHandler) CreateAuthor(w http.ResponseWriter, r *http.Request) {
 // @Accept			json
 // @Produce			json
 // @Param			authorId   path	string	true  "id author"
-// @Success			200		{object}	Response
+// @Success			200		{object}	Response{data=AuthorResponse}
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/author/{authorId} [get]
 func (h *AuthorHandler) GetAuthor(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(r.PathValue("authorId"))
+	id, err := pathID(r, "authorId")
 	if err != nil {
-		h.responder.ErrorBadRequest(w, err)
+		h.responder.ErrorBadRequest(w, r, err)
 		return
 	}
 
 	author, err := h.authorUC.GetAuthor(r.Context(), id)
 	if err != nil {
-		h.responder.ErrorInternal(w, err)
+		h.responder.Error(w, r, err)
 		return
 	}
 
 	h.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    author,
+		Data:    newAuthorResponse(author),
 	})
 }
 
@@ -88,7 +96,8 @@ func (h *AuthorHandler) GetAuthor(w http.ResponseWriter, r *http.Request) {
 // @Accept			json
 // @Produce			json
 // @Param			limit   query	string	true  "limit"
-// @Success			200		{object}	Response
+// @Success			200		{object}	Response{data=[]TopAuthorResponse}
+// @Security		ApiKeyAuth
 // @Router			/author/top [get]
 func (h *AuthorHandler) GetTopAuthors(w http.ResponseWriter, r *http.Request) {
 	limit := 10
@@ -100,13 +109,13 @@ func (h *AuthorHandler) GetTopAuthors(w http.ResponseWriter, r *http.Request) {
 
 	authors, err := h.authorUC.GetTopAuthors(r.Context(), limit)
 	if err != nil {
-		h.responder.ErrorInternal(w, err)
+		h.responder.Error(w, r, err)
 		return
 	}
 
 	h.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    authors,
+		Data:    newTopAuthorResponses(authors),
 	})
 }
 
@@ -115,18 +124,30 @@ func (h *AuthorHandler) GetTopAuthors(w http.ResponseWriter, r *http.Request) {
 // @Tags			author
 // @Accept			json
 // @Produce			json
-// @Success			200		{object}	Response
+// @Param			limit   query	int	false  "page size, 1-100, default 20"
+// @Param			after   query	string	false  "next_cursor of the previous page"
+// @Param			sort   query	string	false  "id, name, created_at, prefix - for descending"
+// @Param			filter   query	[]string	false  "field:op:value on name, created_at; ops eq, ne, lt, lte, gt, gte, like"	collectionFormat(multi)
+// @Success			200		{object}	Response{data=PageResponse{items=[]AuthorResponse}}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/author/all [get]
 func (h *AuthorHandler) GetAllAuthors(w http.ResponseWriter, r *http.Request) {
-	authors, err := h.authorUC.ListAuthors(r.Context())
+	req, err := pageRequest(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	authors, err := h.authorUC.ListAuthors(r.Context(), req)
 	if err != nil {
-		h.responder.ErrorBadRequest(w, err)
+		h.responder.Error(w, r, err)
 		return
 	}
 
 	h.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    authors,
+		Data:    newPageResponse(authors, newAuthorResponses),
 	})
 }
 
@@ -136,24 +157,26 @@ func (h *AuthorHandler) GetAllAuthors(w http.ResponseWriter, r *http.Request) {
 // @Accept			json
 // @Produce			json
 // @Param			authorId   path	string	true  "id author"
-// @Success			200		{object}	Response
+// @Success			200		{object}	Response{data=Data}
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/author/{authorId} [delete]
 func (h *AuthorHandler) DeleteAuthor(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.Atoi(r.PathValue("authorId"))
+	id, err := pathID(r, "authorId")
 	if err != nil {
-		h.responder.ErrorBadRequest(w, err)
+		h.responder.ErrorBadRequest(w, r, err)
 		return
 	}
 
 	err = h.authorUC.DeleteAuthor(r.Context(), id)
 	if err != nil {
-		h.responder.ErrorInternal(w, err)
+		h.responder.Error(w, r, err)
 		return
 	}
 
 	h.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    "author is delete",
+		Data:    Data{Message: "author has been deleted"},
 	})
 }
 
@@ -163,22 +186,101 @@ func (h *AuthorHandler) DeleteAuthor(w http.ResponseWriter, r *http.Request) {
 // @Accept			json
 // @Produce			json
 // @Param			authorId   path	string	true  "authorId"
-// @Success			200		{object}	Response
+// @Param			limit   query	int	false  "page size, 1-100, default 20"
+// @Param			after   query	string	false  "next_cursor of the previous page"
+// @Param			sort   query	string	false  "id, title, created_at, prefix - for descending"
+// @Param			filter   query	[]string	false  "field:op:value on author_id, available, title, genre, created_at; ops eq, ne, lt, lte, gt, gte, like"	collectionFormat(multi)
+// @Success			200		{object}	Response{data=PageResponse{items=[]BookResponse}}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/author/books/{authorId} [get]
 func (h *AuthorHandler) GetByBooksAuthor(w http.ResponseWriter, r *http.Request) {
-	bookID, err := strconv.Atoi(r.PathValue("authorId"))
+	authorID, err := pathID(r, "authorId")
 	if err != nil {
-		h.responder.ErrorBadRequest(w, err)
+		h.responder.ErrorBadRequest(w, r, err)
 		return
 	}
 
-	books, err := h.authorUC.GetByBooksAuthor(r.Context(), bookID)
+	req, err := pageRequest(r)
 	if err != nil {
-		h.responder.ErrorInternal(w, err)
+		h.responder.ErrorBadRequest(w, r, err)
 		return
 	}
+
+	books, err := h.authorUC.ListAuthorBooks(r.Context(), authorID, req)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newPageResponse(books, newBookResponses),
+	})
+}
+
+// @Summary			replace author
+// @Description		replace author name and biography
+// @Tags			author
+// @Accept			json
+// @Produce			json
+// @Param			authorId   path	string	true  "authorId"
+// @Param			author   body	UpdateAuthorRequest	true  "author"
+// @Success			200		{object}	Response{data=AuthorResponse}
+// @Failure			404		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/author/{authorId} [put]
+func (h *AuthorHandler) UpdateAuthor(w http.ResponseWriter, r *http.Request) {
+	h.update(w, r, func(*domain.Author) (UpdateAuthorRequest, error) {
+		return replaceRequest[UpdateAuthorRequest](r)
+	})
+}
+
+// @Summary			patch author
+// @Description		update author fields with a JSON Merge Patch document
+// @Tags			author
+// @Accept			json
+// @Produce			json
+// @Param			authorId   path	string	true  "authorId"
+// @Param			patch   body	UpdateAuthorRequest	true  "merge patch"
+// @Success			200		{object}	Response{data=AuthorResponse}
+// @Failure			404		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/author/{authorId} [patch]
+func (h *AuthorHandler) PatchAuthor(w http.ResponseWriter, r *http.Request) {
+	h.update(w, r, func(author *domain.Author) (UpdateAuthorRequest, error) {
+		return patchRequest(r, newUpdateAuthorRequest(author))
+	})
+}
+
+func (h *AuthorHandler) update(w http.ResponseWriter, r *http.Request, read func(*domain.Author) (UpdateAuthorRequest, error)) {
+	authorID, err := pathID(r, "authorId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	author, err := h.authorUC.GetAuthor(r.Context(), authorID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	req, err := read(author)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+	req.apply(author)
+
+	if err := h.authorUC.UpdateAuthor(r.Context(), author); err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
 	h.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    books,
+		Data:    newAuthorResponse(author),
 	})
 }
@@ -6,12 +6,16 @@ import (
 	"library/internal/usecase"
 	"library/responder"
 	"net/http"
-	"strconv"
+	"strings"
 )
 
 type Booker interface {
 	AddBook(w http.ResponseWriter, r *http.Request)
 	GetBook(w http.ResponseWriter, r *http.Request)
+	GetBookByISBN(w http.ResponseWriter, r *http.Request)
+	ListBooks(w http.ResponseWriter, r *http.Request)
+	UpdateBook(w http.ResponseWriter, r *http.Request)
+	PatchBook(w http.ResponseWriter, r *http.Request)
 	DeleteBook(w http.ResponseWriter, r *http.Request)
 }
 
@@ -32,24 +36,29 @@ func NewBookHandler(bookUC usecase.Booker, responder responder.Responder) Booker
 // @Tags			book
 // @Accept			json
 // @Produce			json
-// @Param			book   body	domain.Book	true  "book"
-// @Success			200		{object}	Response
+// @Param			book   body	CreateBookRequest	true  "book"
+// @Success			200		{object}	Response{data=BookResponse}
+// @Failure			409		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/book [post]
 func (h *BookHandler) AddBook(w http.ResponseWriter, r *http.Request) {
-	var book domain.Book
-	if err := json.NewDecoder(r.Body).Decode(&book); err != nil {
-		h.responder.ErrorBadRequest(w, err)
+	var req CreateBookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
 		return
 	}
 
+	book := req.toDomain()
+
 	if err := h.bookUC.AddBook(r.Context(), &book); err != nil {
-		h.responder.ErrorInternal(w, err)
+		h.responder.Error(w, r, err)
 		return
 	}
 
 	h.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    book,
+		Data:    newBookResponse(&book),
 	})
 }
 
@@ -59,50 +68,199 @@ func (h *BookHandler) AddBook(w http.ResponseWriter, r *http.Request) {
 // @Accept			json
 // @Produce			json
 // @Param			bookId   path	string	true  "id book"
-// @Success			200		{object}	Response
+// @Success			200		{object}	Response{data=BookResponse}
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/book/{bookId} [get]
 func (h *BookHandler) GetBook(w http.ResponseWriter, r *http.Request) {
-	bookID, err := strconv.Atoi(r.PathValue("bookId"))
+	bookID, err := pathID(r, "bookId")
 	if err != nil {
-		h.responder.ErrorBadRequest(w, err)
+		h.responder.ErrorBadRequest(w, r, err)
 		return
 	}
 
 	book, err := h.bookUC.GetBook(r.Context(), bookID)
 	if err != nil {
-		h.responder.ErrorInternal(w, err)
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newBookResponse(book),
+	})
+}
+
+// @Summary			get book by ISBN
+// @Description		find a book by ISBN-10 or ISBN-13, hyphens allowed
+// @Tags			book
+// @Accept			json
+// @Produce			json
+// @Param			isbn   path	string	true  "ISBN-10 or ISBN-13"
+// @Success			200		{object}	Response{data=BookResponse}
+// @Failure			404		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/book/isbn/{isbn} [get]
+func (h *BookHandler) GetBookByISBN(w http.ResponseWriter, r *http.Request) {
+	book, err := h.bookUC.GetBookByISBN(r.Context(), r.PathValue("isbn"))
+	if err != nil {
+		h.responder.Error(w, r, err)
 		return
 	}
 
 	h.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    book,
+		Data:    newBookResponse(book),
 	})
 }
 
+// @Summary			list books
+// @Description		list catalogue books with their authors
+// @Tags			book
+// @Accept			json
+// @Produce			json
+// @Param			limit   query	int	false  "page size, 1-100, default 20"
+// @Param			after   query	string	false  "next_cursor of the previous page"
+// @Param			sort   query	string	false  "id, title, created_at, prefix - for descending"
+// @Param			filter   query	[]string	false  "field:op:value on author_id, available, title, genre, created_at; ops eq, ne, lt, lte, gt, gte, like"	collectionFormat(multi)
+// @Param			author_id   query	int	false  "shortcut for filter=author_id:eq:<id>"
+// @Param			available   query	bool	false  "shortcut for filter=available:eq:<bool>"
+// @Param			title   query	string	false  "shortcut for filter=title:like:<text>"
+// @Success			200		{object}	Response{data=PageResponse{items=[]BookResponse}}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/book [get]
+func (h *BookHandler) ListBooks(w http.ResponseWriter, r *http.Request) {
+	req, err := pageRequest(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+	req.Filters = append(req.Filters, bookFilters(r)...)
+
+	books, err := h.bookUC.ListBooks(r.Context(), req)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newPageResponse(books, newBookResponses),
+	})
+}
+
+// bookFilters переводит короткие параметры каталога в общие фильтры списка
+func bookFilters(r *http.Request) []domain.Filter {
+	q := r.URL.Query()
+	var filters []domain.Filter
+	if v := q.Get("author_id"); v != "" {
+		filters = append(filters, domain.Filter{Field: "author_id", Op: domain.FilterEq, Value: v})
+	}
+	if v := q.Get("available"); v != "" {
+		filters = append(filters, domain.Filter{Field: "available", Op: domain.FilterEq, Value: v})
+	}
+	if v := strings.TrimSpace(q.Get("title")); v != "" {
+		filters = append(filters, domain.Filter{Field: "title", Op: domain.FilterLike, Value: v})
+	}
+	return filters
+}
+
 // @Summary			delete book
 // @Description		delete book
 // @Tags			book
 // @Accept			json
 // @Produce			json
 // @Param			bookId   path	string	true  "id book"
-// @Success			200		{object}	Response
+// @Success			200		{object}	Response{data=Data}
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/book/{bookId} [delete]
 func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
-	bookID, err := strconv.Atoi(r.PathValue("bookId"))
+	bookID, err := pathID(r, "bookId")
 	if err != nil {
-		h.responder.ErrorBadRequest(w, err)
+		h.responder.ErrorBadRequest(w, r, err)
 		return
 	}
 
 	err = h.bookUC.DeleteBook(r.Context(), bookID)
 	if err != nil {
-		h.responder.ErrorInternal(w, err)
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    Data{Message: "book has been deleted"},
+	})
+}
+
+// @Summary			replace book
+// @Description		replace book fields; availability follows the book copies and is not set here
+// @Tags			book
+// @Accept			json
+// @Produce			json
+// @Param			bookId   path	string	true  "id book"
+// @Param			book   body	UpdateBookRequest	true  "book"
+// @Success			200		{object}	Response{data=BookResponse}
+// @Failure			404		{object}	responder.Problem
+// @Failure			409		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/book/{bookId} [put]
+func (h *BookHandler) UpdateBook(w http.ResponseWriter, r *http.Request) {
+	h.update(w, r, func(*domain.Book) (UpdateBookRequest, error) {
+		return replaceRequest[UpdateBookRequest](r)
+	})
+}
+
+// @Summary			patch book
+// @Description		update book fields with a JSON Merge Patch document
+// @Tags			book
+// @Accept			json
+// @Produce			json
+// @Param			bookId   path	string	true  "id book"
+// @Param			patch   body	UpdateBookRequest	true  "merge patch"
+// @Success			200		{object}	Response{data=BookResponse}
+// @Failure			404		{object}	responder.Problem
+// @Failure			409		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/book/{bookId} [patch]
+func (h *BookHandler) PatchBook(w http.ResponseWriter, r *http.Request) {
+	h.update(w, r, func(book *domain.Book) (UpdateBookRequest, error) {
+		return patchRequest(r, newUpdateBookRequest(book))
+	})
+}
+
+func (h *BookHandler) update(w http.ResponseWriter, r *http.Request, read func(*domain.Book) (UpdateBookRequest, error)) {
+	bookID, err := pathID(r, "bookId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	book, err := h.bookUC.GetBook(r.Context(), bookID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	req, err := read(book)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+	req.apply(book)
+
+	if err := h.bookUC.UpdateBook(r.Context(), book); err != nil {
+		h.responder.Error(w, r, err)
 		return
 	}
 
 	h.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    "book id delete",
+		Data:    newBookResponse(book),
 	})
 }
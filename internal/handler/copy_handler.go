@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"library/internal/domain"
+	"library/internal/facade"
+	"library/responder"
+	"net/http"
+)
+
+type Copier interface {
+	AddCopy(w http.ResponseWriter, r *http.Request)
+	ListCopies(w http.ResponseWriter, r *http.Request)
+	RetireCopy(w http.ResponseWriter, r *http.Request)
+}
+
+type CopyHandler struct {
+	library   facade.Facader
+	responder responder.Responder
+}
+
+func NewCopyHandler(library facade.Facader, responder responder.Responder) Copier {
+	return &CopyHandler{
+		library:   library,
+		responder: responder,
+	}
+}
+
+// @Summary			add copy
+// @Description		add a physical copy of a book; if patrons are waiting, the copy is put on the hold shelf for the first of them.
+// @Description		Barcodes of the form B000000000 (optionally with -n) are reserved for generated barcodes
+// @Tags			copy
+// @Accept			json
+// @Produce			json
+// @Param			bookId   path	string	true  "id book"
+// @Param			copy   body	AddCopyRequest	true  "copy"
+// @Success			200		{object}	Response{data=CopyResponse}
+// @Failure			404		{object}	responder.Problem
+// @Failure			409		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/book/{bookId}/copies [post]
+func (h *CopyHandler) AddCopy(w http.ResponseWriter, r *http.Request) {
+	bookID, err := pathID(r, "bookId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	var req AddCopyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	c := domain.Copy{BookID: bookID, Barcode: req.Barcode}
+	if err := h.library.AddCopy(r.Context(), &c); err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newCopyResponse(&c),
+	})
+}
+
+// @Summary			list copies
+// @Description		list physical copies of a book, including retired ones
+// @Tags			copy
+// @Accept			json
+// @Produce			json
+// @Param			bookId   path	string	true  "id book"
+// @Success			200		{object}	Response{data=[]CopyResponse}
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/book/{bookId}/copies [get]
+func (h *CopyHandler) ListCopies(w http.ResponseWriter, r *http.Request) {
+	bookID, err := pathID(r, "bookId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	copies, err := h.library.ListCopies(r.Context(), bookID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newCopyResponses(copies),
+	})
+}
+
+// @Summary			retire copy
+// @Description		retire a lost or worn-out copy; a copy on loan or on the hold shelf cannot be retired
+// @Tags			copy
+// @Accept			json
+// @Produce			json
+// @Param			copyId   path	string	true  "id copy"
+// @Success			200		{object}	Response{data=Data}
+// @Failure			404		{object}	responder.Problem
+// @Failure			409		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/copy/{copyId} [delete]
+func (h *CopyHandler) RetireCopy(w http.ResponseWriter, r *http.Request) {
+	copyID, err := pathID(r, "copyId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if err := h.library.RetireCopy(r.Context(), copyID); err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    Data{Message: "copy has been retired"},
+	})
+}
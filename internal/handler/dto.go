@@ -0,0 +1,551 @@
+package handler
+
+import (
+	"library/internal/auth"
+	"library/internal/domain"
+	"library/internal/isbn"
+	"time"
+)
+
+// Типы запросов и ответов API. Контракт API описан только здесь,
+// доменные модели в JSON напрямую не отдаются.
+
+type AuthorBookRequest struct {
+	Title string `json:"title" example:"War and Peace"`
+	Genre string `json:"genre" example:"novel"`
+}
+
+type CreateAuthorRequest struct {
+	Name      string              `json:"name" example:"Leo Tolstoy"`
+	Biography string              `json:"biography"`
+	Books     []AuthorBookRequest `json:"books,omitempty"`
+}
+
+func (req CreateAuthorRequest) toDomain() domain.Author {
+	author := domain.Author{
+		Name:      req.Name,
+		Biography: req.Biography,
+	}
+	for _, b := range req.Books {
+		author.Books = append(author.Books, domain.Book{Title: b.Title, Genre: b.Genre})
+	}
+	return author
+}
+
+// CreateBookRequest - запись каталога; книга становится доступной, когда у неё появляется экземпляр
+type CreateBookRequest struct {
+	Title           string  `json:"title" example:"War and Peace"`
+	AuthorID        int     `json:"author_id" example:"1"`
+	Genre           string  `json:"genre" example:"novel"`
+	LoanPeriodDays  *int    `json:"loan_period_days,omitempty" example:"14"`
+	ISBN            *string `json:"isbn,omitempty" example:"978-5-17-090049-7"`
+	PublicationYear *int    `json:"publication_year,omitempty" example:"1869"`
+}
+
+func (req CreateBookRequest) toDomain() domain.Book {
+	return domain.Book{
+		Title:           req.Title,
+		AuthorID:        req.AuthorID,
+		Genre:           req.Genre,
+		LoanPeriodDays:  req.LoanPeriodDays,
+		ISBN:            req.ISBN,
+		PublicationYear: req.PublicationYear,
+		CreatedAt:       time.Now(),
+	}
+}
+
+type AuthorSummary struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// BookResponse - запись каталога; available означает, что на полке есть хотя бы один экземпляр,
+// isbn хранится в форме ISBN-13, isbn_10 есть только у номеров с префиксом 978
+type BookResponse struct {
+	ID              int            `json:"id"`
+	Title           string         `json:"title"`
+	AuthorID        int            `json:"author_id"`
+	Author          *AuthorSummary `json:"author,omitempty"`
+	Available       bool           `json:"available"`
+	Genre           string         `json:"genre"`
+	LoanPeriodDays  *int           `json:"loan_period_days"`
+	ISBN            *string        `json:"isbn" example:"9785170900497"`
+	ISBN10          *string        `json:"isbn_10" example:"517090049X"`
+	PublicationYear *int           `json:"publication_year"`
+	CreatedAt       time.Time      `json:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+func newBookResponse(b *domain.Book) BookResponse {
+	resp := BookResponse{
+		ID:              b.ID,
+		Title:           b.Title,
+		AuthorID:        b.AuthorID,
+		Available:       b.Available,
+		Genre:           b.Genre,
+		LoanPeriodDays:  b.LoanPeriodDays,
+		ISBN:            b.ISBN,
+		PublicationYear: b.PublicationYear,
+		CreatedAt:       b.CreatedAt,
+	}
+	if b.ISBN != nil {
+		if isbn10, ok := isbn.To10(*b.ISBN); ok {
+			resp.ISBN10 = &isbn10
+		}
+	}
+	if b.Author != nil {
+		resp.Author = &AuthorSummary{ID: b.Author.ID, Name: b.Author.Name}
+	}
+	return resp
+}
+
+func newBookResponses(books []domain.Book) []BookResponse {
+	resp := make([]BookResponse, 0, len(books))
+	for i := range books {
+		resp = append(resp, newBookResponse(&books[i]))
+	}
+	return resp
+}
+
+type CopyResponse struct {
+	ID        int        `json:"id"`
+	BookID    int        `json:"book_id"`
+	Barcode   string     `json:"barcode"`
+	Available bool       `json:"available"`
+	RetiredAt *time.Time `json:"retired_at" swaggertype:"string" format:"date-time"`
+	CreatedAt time.Time  `json:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+func newCopyResponse(c *domain.Copy) CopyResponse {
+	return CopyResponse(*c)
+}
+
+func newCopyResponses(copies []domain.Copy) []CopyResponse {
+	resp := make([]CopyResponse, 0, len(copies))
+	for i := range copies {
+		resp = append(resp, newCopyResponse(&copies[i]))
+	}
+	return resp
+}
+
+type AddCopyRequest struct {
+	Barcode string `json:"barcode" example:"LIB-0042"`
+}
+
+type AuthorResponse struct {
+	ID        int            `json:"id"`
+	Name      string         `json:"name"`
+	Biography string         `json:"biography"`
+	Books     []BookResponse `json:"books"`
+	CreatedAt time.Time      `json:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+func newAuthorResponse(a *domain.Author) AuthorResponse {
+	return AuthorResponse{
+		ID:        a.ID,
+		Name:      a.Name,
+		Biography: a.Biography,
+		Books:     newBookResponses(a.Books),
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+func newAuthorResponses(authors []domain.Author) []AuthorResponse {
+	resp := make([]AuthorResponse, 0, len(authors))
+	for i := range authors {
+		resp = append(resp, newAuthorResponse(&authors[i]))
+	}
+	return resp
+}
+
+type TopAuthorResponse struct {
+	Author    AuthorResponse `json:"author"`
+	RentCount int            `json:"rent_count"`
+}
+
+func newTopAuthorResponses(authors []*domain.AuthorWithRentCount) []TopAuthorResponse {
+	resp := make([]TopAuthorResponse, 0, len(authors))
+	for _, a := range authors {
+		resp = append(resp, TopAuthorResponse{Author: newAuthorResponse(&a.Author), RentCount: a.RentCount})
+	}
+	return resp
+}
+
+type RentalResponse struct {
+	ID         int        `json:"id"`
+	BookID     int        `json:"book_id"`
+	CopyID     int        `json:"copy_id"`
+	UserID     int        `json:"user_id"`
+	RentalDate time.Time  `json:"rental_date" swaggertype:"string" format:"date-time"`
+	DueDate    time.Time  `json:"due_date" swaggertype:"string" format:"date-time"`
+	ReturnDate *time.Time `json:"return_date" swaggertype:"string" format:"date-time"`
+	Renewals   int        `json:"renewals"`
+	Overdue    bool       `json:"overdue"`
+}
+
+func newRentalResponse(r *domain.BookRental) RentalResponse {
+	return RentalResponse{
+		ID:         r.ID,
+		BookID:     r.BookID,
+		CopyID:     r.CopyID,
+		UserID:     r.UserID,
+		RentalDate: r.RentalDate,
+		DueDate:    r.DueDate,
+		ReturnDate: r.ReturnDate,
+		Renewals:   r.Renewals,
+		Overdue:    r.Overdue,
+	}
+}
+
+func newRentalResponses(rentals []domain.BookRental) []RentalResponse {
+	resp := make([]RentalResponse, 0, len(rentals))
+	for i := range rentals {
+		resp = append(resp, newRentalResponse(&rentals[i]))
+	}
+	return resp
+}
+
+type LoanResponse struct {
+	RentalResponse
+	Book BookResponse `json:"book"`
+}
+
+func newLoanResponses(loans []domain.Loan) []LoanResponse {
+	resp := make([]LoanResponse, 0, len(loans))
+	for i := range loans {
+		resp = append(resp, LoanResponse{
+			RentalResponse: newRentalResponse(&loans[i].BookRental),
+			Book:           newBookResponse(&loans[i].Book),
+		})
+	}
+	return resp
+}
+
+type OverdueRentalResponse struct {
+	RentalResponse
+	BookTitle string `json:"book_title"`
+	UserName  string `json:"user_name"`
+	UserEmail string `json:"user_email"`
+}
+
+func newOverdueResponses(rentals []domain.OverdueRental) []OverdueRentalResponse {
+	resp := make([]OverdueRentalResponse, 0, len(rentals))
+	for i := range rentals {
+		resp = append(resp, OverdueRentalResponse{
+			RentalResponse: newRentalResponse(&rentals[i].BookRental),
+			BookTitle:      rentals[i].BookTitle,
+			UserName:       rentals[i].UserName,
+			UserEmail:      rentals[i].UserEmail,
+		})
+	}
+	return resp
+}
+
+type UserResponse struct {
+	ID          int              `json:"id"`
+	Name        string           `json:"name"`
+	Email       string           `json:"email"`
+	Role        string           `json:"role"`
+	BirthDate   *string          `json:"birth_date" format:"date" example:"1990-01-31"`
+	Balance     int64            `json:"balance"`
+	RentedBooks []RentalResponse `json:"rented_books"`
+	CreatedAt   time.Time        `json:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+func newUserResponse(u *domain.User) UserResponse {
+	resp := UserResponse{
+		ID:          u.ID,
+		Name:        u.Name,
+		Email:       u.Email,
+		Role:        u.Role,
+		Balance:     u.Balance,
+		RentedBooks: newRentalResponses(u.RentedBooks),
+		CreatedAt:   u.CreatedAt,
+	}
+	if u.BirthDate != nil {
+		birthDate := u.BirthDate.Format(time.DateOnly)
+		resp.BirthDate = &birthDate
+	}
+	return resp
+}
+
+func newUserResponses(users []domain.User) []UserResponse {
+	resp := make([]UserResponse, 0, len(users))
+	for i := range users {
+		resp = append(resp, newUserResponse(&users[i]))
+	}
+	return resp
+}
+
+type LedgerEntryResponse struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	RentalID  *int      `json:"rental_id"`
+	Kind      string    `json:"kind" enums:"fine,payment,waiver"`
+	Amount    int64     `json:"amount"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+func newLedgerEntryResponse(e *domain.LedgerEntry) LedgerEntryResponse {
+	return LedgerEntryResponse{
+		ID:        e.ID,
+		UserID:    e.UserID,
+		RentalID:  e.RentalID,
+		Kind:      e.Kind,
+		Amount:    e.Amount,
+		Note:      e.Note,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+func newLedgerEntryResponses(entries []domain.LedgerEntry) []LedgerEntryResponse {
+	resp := make([]LedgerEntryResponse, 0, len(entries))
+	for i := range entries {
+		resp = append(resp, newLedgerEntryResponse(&entries[i]))
+	}
+	return resp
+}
+
+type HoldResponse struct {
+	ID        int        `json:"id"`
+	BookID    int        `json:"book_id"`
+	CopyID    *int       `json:"copy_id"`
+	UserID    int        `json:"user_id"`
+	Status    string     `json:"status" enums:"waiting,ready,fulfilled,expired,cancelled"`
+	ReadyAt   *time.Time `json:"ready_at" swaggertype:"string" format:"date-time"`
+	ExpiresAt *time.Time `json:"expires_at" swaggertype:"string" format:"date-time"`
+	CreatedAt time.Time  `json:"created_at" swaggertype:"string" format:"date-time"`
+}
+
+func newHoldResponse(h *domain.Hold) HoldResponse {
+	return HoldResponse{
+		ID:        h.ID,
+		BookID:    h.BookID,
+		CopyID:    h.CopyID,
+		UserID:    h.UserID,
+		Status:    h.Status,
+		ReadyAt:   h.ReadyAt,
+		ExpiresAt: h.ExpiresAt,
+		CreatedAt: h.CreatedAt,
+	}
+}
+
+func newHoldResponses(holds []domain.Hold) []HoldResponse {
+	resp := make([]HoldResponse, 0, len(holds))
+	for i := range holds {
+		resp = append(resp, newHoldResponse(&holds[i]))
+	}
+	return resp
+}
+
+type APIKeyResponse struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *int       `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" swaggertype:"string" format:"date-time"`
+	LastUsedAt *time.Time `json:"last_used_at" swaggertype:"string" format:"date-time"`
+	RevokedAt  *time.Time `json:"revoked_at" swaggertype:"string" format:"date-time"`
+}
+
+func newAPIKeyResponse(k *domain.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
+
+func newAPIKeyResponses(keys []domain.APIKey) []APIKeyResponse {
+	resp := make([]APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		resp = append(resp, newAPIKeyResponse(&keys[i]))
+	}
+	return resp
+}
+
+// IssuedAPIKeyResponse - созданный ключ; значение Key показывается только один раз
+type IssuedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// SearchHitResponse - найденная книга или автор; headline содержит совпадения в тегах <mark>
+type SearchHitResponse struct {
+	Kind       string  `json:"kind" enums:"book,author"`
+	ID         int     `json:"id"`
+	Title      string  `json:"title"`
+	AuthorID   int     `json:"author_id"`
+	AuthorName string  `json:"author_name"`
+	Rank       float64 `json:"rank"`
+	Headline   string  `json:"headline"`
+}
+
+func newSearchHitResponses(hits []domain.SearchHit) []SearchHitResponse {
+	resp := make([]SearchHitResponse, 0, len(hits))
+	for _, h := range hits {
+		resp = append(resp, SearchHitResponse(h))
+	}
+	return resp
+}
+
+type SuggestionResponse struct {
+	Kind  string  `json:"kind" enums:"book,author"`
+	ID    int     `json:"id"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+func newSuggestionResponses(suggestions []domain.Suggestion) []SuggestionResponse {
+	resp := make([]SuggestionResponse, 0, len(suggestions))
+	for _, s := range suggestions {
+		resp = append(resp, SuggestionResponse(s))
+	}
+	return resp
+}
+
+type ImportLineResponse struct {
+	Line   int                 `json:"line"`
+	Status string              `json:"status" enums:"accepted,rejected"`
+	BookID *int                `json:"book_id,omitempty"`
+	Errors []domain.FieldError `json:"errors,omitempty"`
+}
+
+// ImportReportResponse - итог импорта по каждой строке файла, строки нумеруются с единицы вместе с заголовком
+type ImportReportResponse struct {
+	Accepted       int                  `json:"accepted"`
+	Rejected       int                  `json:"rejected"`
+	AuthorsCreated int                  `json:"authors_created"`
+	Lines          []ImportLineResponse `json:"lines"`
+}
+
+func newImportReportResponse(report *domain.ImportReport) ImportReportResponse {
+	resp := ImportReportResponse{
+		Accepted:       report.Accepted,
+		Rejected:       report.Rejected,
+		AuthorsCreated: report.AuthorsCreated,
+		Lines:          make([]ImportLineResponse, 0, len(report.Lines)),
+	}
+	for _, l := range report.Lines {
+		line := ImportLineResponse{Line: l.Line, Status: l.Status, Errors: l.Errors}
+		if l.BookID != 0 {
+			bookID := l.BookID
+			line.BookID = &bookID
+		}
+		resp.Lines = append(resp.Lines, line)
+	}
+	return resp
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func newTokenResponse(t *auth.TokenPair) TokenResponse {
+	return TokenResponse{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		TokenType:    t.TokenType,
+		ExpiresIn:    t.ExpiresIn,
+	}
+}
+
+type UpdateAuthorRequest struct {
+	Name      string `json:"name" example:"Leo Tolstoy"`
+	Biography string `json:"biography"`
+}
+
+func newUpdateAuthorRequest(a *domain.Author) UpdateAuthorRequest {
+	return UpdateAuthorRequest{Name: a.Name, Biography: a.Biography}
+}
+
+func (req UpdateAuthorRequest) apply(a *domain.Author) {
+	a.Name = req.Name
+	a.Biography = req.Biography
+}
+
+type UpdateBookRequest struct {
+	Title           string  `json:"title" example:"War and Peace"`
+	AuthorID        int     `json:"author_id" example:"1"`
+	Genre           string  `json:"genre" example:"novel"`
+	LoanPeriodDays  *int    `json:"loan_period_days" example:"14"`
+	ISBN            *string `json:"isbn" example:"978-5-17-090049-7"`
+	PublicationYear *int    `json:"publication_year" example:"1869"`
+}
+
+func newUpdateBookRequest(b *domain.Book) UpdateBookRequest {
+	return UpdateBookRequest{
+		Title:           b.Title,
+		AuthorID:        b.AuthorID,
+		Genre:           b.Genre,
+		LoanPeriodDays:  b.LoanPeriodDays,
+		ISBN:            b.ISBN,
+		PublicationYear: b.PublicationYear,
+	}
+}
+
+func (req UpdateBookRequest) apply(b *domain.Book) {
+	b.Title = req.Title
+	b.AuthorID = req.AuthorID
+	b.Genre = req.Genre
+	b.LoanPeriodDays = req.LoanPeriodDays
+	b.ISBN = req.ISBN
+	b.PublicationYear = req.PublicationYear
+}
+
+type UpdateUserRequest struct {
+	Name      string  `json:"name"`
+	Email     string  `json:"email"`
+	Role      string  `json:"role" enums:"patron,librarian"`
+	BirthDate *string `json:"birth_date" format:"date" example:"1990-01-31"`
+}
+
+func newUpdateUserRequest(u *domain.User) UpdateUserRequest {
+	resp := newUserResponse(u)
+	return UpdateUserRequest{Name: u.Name, Email: u.Email, Role: u.Role, BirthDate: resp.BirthDate}
+}
+
+// apply заменяет профиль целиком; пропущенная роль остаётся прежней, чтобы читателю не приходилось её передавать
+func (req UpdateUserRequest) apply(u *domain.User) error {
+	u.Name = req.Name
+	u.Email = req.Email
+	if req.Role != "" {
+		u.Role = req.Role
+	}
+	u.BirthDate = nil
+	if req.BirthDate != nil {
+		birthDate, err := time.Parse(time.DateOnly, *req.BirthDate)
+		if err != nil {
+			return invalidField("birth_date", "must be a date in YYYY-MM-DD format")
+		}
+		u.BirthDate = &birthDate
+	}
+	return nil
+}
+
+// PageResponse - страница списка; next_cursor передаётся в after за следующей страницей и равен null на последней
+type PageResponse struct {
+	Items         interface{} `json:"items"`
+	NextCursor    *string     `json:"next_cursor"`
+	TotalEstimate int64       `json:"total_estimate"`
+}
+
+func newPageResponse[T, R any](page *domain.Page[T], items func([]T) []R) PageResponse {
+	resp := PageResponse{
+		Items:         items(page.Items),
+		TotalEstimate: page.TotalEstimate,
+	}
+	if page.NextCursor != "" {
+		resp.NextCursor = &page.NextCursor
+	}
+	return resp
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"library/internal/domain"
+	"testing"
+)
+
+func TestUpdateUserRequestKeepsOmittedRole(t *testing.T) {
+	user := &domain.User{Name: "Old", Email: "old@example.com", Role: domain.RolePatron}
+	if err := (UpdateUserRequest{Name: "New", Email: "new@example.com"}).apply(user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != domain.RolePatron || user.Name != "New" {
+		t.Fatalf("apply() = %+v, want new name and the old role", user)
+	}
+
+	if err := (UpdateUserRequest{Name: "New", Email: "new@example.com", Role: domain.RoleLibrarian}).apply(user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != domain.RoleLibrarian {
+		t.Fatalf("role = %q, want %q", user.Role, domain.RoleLibrarian)
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"library/internal/domain"
+	"library/internal/usecase"
+	"library/responder"
+	"net/http"
+	"time"
+)
+
+type Exporter interface {
+	Export(w http.ResponseWriter, r *http.Request)
+}
+
+type ExportHandler struct {
+	exportUC  usecase.Exporter
+	responder responder.Responder
+}
+
+func NewExportHandler(exportUC usecase.Exporter, responder responder.Responder) Exporter {
+	return &ExportHandler{
+		exportUC:  exportUC,
+		responder: responder,
+	}
+}
+
+// exportFiles - тип содержимого и расширение файла выгрузки по формату
+var exportFiles = map[string]struct{ contentType, ext string }{
+	domain.FormatCSV:   {"text/csv; charset=utf-8", "csv"},
+	domain.FormatExcel: {"text/csv; charset=utf-8", "csv"},
+	domain.FormatJSONL: {"application/x-ndjson", "jsonl"},
+}
+
+// @Summary			export data
+// @Description		stream a full snapshot of books (with author), authors, users or rentals.
+// @Description		format=excel is CSV with a UTF-8 BOM and CRLF line endings, text cells that look like formulas are prefixed with an apostrophe.
+// @Tags			export
+// @Produce			text/csv
+// @Produce			application/x-ndjson
+// @Param			dataset  path	string	true  "data set" Enums(books, authors, users, rentals)
+// @Param			format   query	string	false "file format" Enums(csv, jsonl, excel) default(csv)
+// @Success			200		{string}	string
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/export/{dataset} [get]
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	dataset := r.PathValue("dataset")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = domain.FormatCSV
+	}
+
+	extendDeadlines(w)
+	file := exportFiles[format]
+	out := &streamWriter{
+		ResponseWriter: w,
+		contentType:    file.contentType,
+		filename:       fmt.Sprintf("%s-%s.%s", dataset, time.Now().Format("20060102"), file.ext),
+	}
+	if err := h.exportUC.Export(r.Context(), dataset, format, out); err != nil {
+		out.fail(h.responder, r, err)
+	}
+}
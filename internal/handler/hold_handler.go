@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"library/internal/auth"
+	"library/internal/facade"
+	"library/responder"
+	"net/http"
+)
+
+type Holder interface {
+	PlaceHold(w http.ResponseWriter, r *http.Request)
+	CancelHold(w http.ResponseWriter, r *http.Request)
+	ListBookHolds(w http.ResponseWriter, r *http.Request)
+	ListUserHolds(w http.ResponseWriter, r *http.Request)
+}
+
+type HoldHandler struct {
+	holdUC    facade.Facader
+	responder responder.Responder
+}
+
+func NewHoldHandler(holdUC facade.Facader, responder responder.Responder) Holder {
+	return &HoldHandler{
+		holdUC:    holdUC,
+		responder: responder,
+	}
+}
+
+// @Summary			place hold
+// @Description		place a hold on a lent-out book
+// @Tags			hold
+// @Accept			json
+// @Produce			json
+// @Param			bookId   path	string	true  "bookId"
+// @Param			userId   path	string	true  "userId"
+// @Success			200		{object}	Response{data=HoldResponse}
+// @Failure			409		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/hold/{bookId}/{userId} [post]
+func (h *HoldHandler) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	bookID, err := pathID(r, "bookId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	userID, err := pathID(r, "userId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if !auth.CanActFor(r.Context(), userID) {
+		h.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
+		return
+	}
+
+	hold, err := h.holdUC.PlaceHold(r.Context(), bookID, userID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newHoldResponse(hold),
+	})
+}
+
+// @Summary			cancel hold
+// @Description		cancel hold
+// @Tags			hold
+// @Accept			json
+// @Produce			json
+// @Param			holdId   path	string	true  "holdId"
+// @Success			200		{object}	Response{data=Data}
+// @Failure			409		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/hold/{holdId} [delete]
+func (h *HoldHandler) CancelHold(w http.ResponseWriter, r *http.Request) {
+	holdID, err := pathID(r, "holdId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	hold, err := h.holdUC.GetHold(r.Context(), holdID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+	if !auth.CanActFor(r.Context(), hold.UserID) {
+		h.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
+		return
+	}
+
+	if err := h.holdUC.CancelHold(r.Context(), holdID); err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data: Data{
+			Message: "hold cancelled",
+		},
+	})
+}
+
+// @Summary			list book holds
+// @Description		list active holds on a book in queue order
+// @Tags			hold
+// @Accept			json
+// @Produce			json
+// @Param			bookId   path	string	true  "bookId"
+// @Success			200		{object}	Response{data=[]HoldResponse}
+// @Security		ApiKeyAuth
+// @Router			/hold/book/{bookId} [get]
+func (h *HoldHandler) ListBookHolds(w http.ResponseWriter, r *http.Request) {
+	bookID, err := pathID(r, "bookId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	holds, err := h.holdUC.ListBookHolds(r.Context(), bookID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newHoldResponses(holds),
+	})
+}
+
+// @Summary			list user holds
+// @Description		list active holds of a user
+// @Tags			hold
+// @Accept			json
+// @Produce			json
+// @Param			userId   path	string	true  "userId"
+// @Success			200		{object}	Response{data=[]HoldResponse}
+// @Security		ApiKeyAuth
+// @Router			/user/{userId}/holds [get]
+func (h *HoldHandler) ListUserHolds(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathID(r, "userId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if !auth.CanActFor(r.Context(), userID) {
+		h.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
+		return
+	}
+
+	holds, err := h.holdUC.ListUserHolds(r.Context(), userID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newHoldResponses(holds),
+	})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"library/internal/usecase"
+	"library/responder"
+	"net/http"
+)
+
+// maxImportSize - предельный размер файла импорта каталога
+const maxImportSize = 64 << 20
+
+type Importer interface {
+	ImportBooks(w http.ResponseWriter, r *http.Request)
+}
+
+type ImportHandler struct {
+	importUC  usecase.Importer
+	responder responder.Responder
+}
+
+func NewImportHandler(importUC usecase.Importer, responder responder.Responder) Importer {
+	return &ImportHandler{
+		importUC:  importUC,
+		responder: responder,
+	}
+}
+
+// @Summary			import catalogue
+// @Description		bulk import books from CSV with a header row: title and author are required, isbn and copies (default 1) are optional.
+// @Description		Authors are matched by name or created. Valid rows are loaded in one transaction, the report lists every line.
+// @Tags			book
+// @Accept			text/csv
+// @Produce			json
+// @Param			file   body	string	true  "CSV file"
+// @Success			200		{object}	Response{data=ImportReportResponse}
+// @Failure			400		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/book/import [post]
+func (h *ImportHandler) ImportBooks(w http.ResponseWriter, r *http.Request) {
+	extendDeadlines(w)
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	report, err := h.importUC.ImportCSV(r.Context(), body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.responder.ErrorBadRequest(w, r, invalidField("file", "must be at most 64 MB"))
+		return
+	}
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newImportReportResponse(report),
+	})
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"library/internal/auth"
+	"library/internal/domain"
+	"library/internal/usecase"
+	"library/responder"
+	"net/http"
+	"strconv"
+)
+
+type Ledgerer interface {
+	Pay(w http.ResponseWriter, r *http.Request)
+	Waive(w http.ResponseWriter, r *http.Request)
+	GetLedger(w http.ResponseWriter, r *http.Request)
+}
+
+type LedgerHandler struct {
+	ledgerUC  usecase.Ledgerer
+	responder responder.Responder
+}
+
+func NewLedgerHandler(ledgerUC usecase.Ledgerer, responder responder.Responder) Ledgerer {
+	return &LedgerHandler{
+		ledgerUC:  ledgerUC,
+		responder: responder,
+	}
+}
+
+// @Summary			pay fines
+// @Description		record a payment against user's outstanding fines
+// @Tags			ledger
+// @Accept			x-www-form-urlencoded
+// @Produce			json
+// @Param			userId   path	string	true  "userId"
+// @Param amount   	formData	int	true  "amount in kopecks"
+// @Param note   	formData	string	false  "note"
+// @Success			200		{object}	Response{data=LedgerEntryResponse}
+// @Security		ApiKeyAuth
+// @Router			/user/{userId}/payments [post]
+func (h *LedgerHandler) Pay(w http.ResponseWriter, r *http.Request) {
+	h.settle(w, r, h.ledgerUC.Pay)
+}
+
+// @Summary			waive fines
+// @Description		waive part of user's outstanding fines
+// @Tags			ledger
+// @Accept			x-www-form-urlencoded
+// @Produce			json
+// @Param			userId   path	string	true  "userId"
+// @Param amount   	formData	int	true  "amount in kopecks"
+// @Param note   	formData	string	false  "note"
+// @Success			200		{object}	Response{data=LedgerEntryResponse}
+// @Security		ApiKeyAuth
+// @Router			/user/{userId}/waivers [post]
+func (h *LedgerHandler) Waive(w http.ResponseWriter, r *http.Request) {
+	h.settle(w, r, h.ledgerUC.Waive)
+}
+
+// @Summary			get ledger
+// @Description		list user's fines, payments and waivers
+// @Tags			ledger
+// @Accept			json
+// @Produce			json
+// @Param			userId   path	string	true  "userId"
+// @Param			limit   query	int	false  "page size, 1-100, default 20"
+// @Param			after   query	string	false  "next_cursor of the previous page"
+// @Param			sort   query	string	false  "id, amount, created_at (default), prefix - for descending"
+// @Param			filter   query	[]string	false  "field:op:value on kind, amount, rental_id, created_at; ops eq, ne, lt, lte, gt, gte, like"	collectionFormat(multi)
+// @Success			200		{object}	Response{data=PageResponse{items=[]LedgerEntryResponse}}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/user/{userId}/ledger [get]
+func (h *LedgerHandler) GetLedger(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathID(r, "userId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if !auth.CanActFor(r.Context(), userID) {
+		h.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
+		return
+	}
+
+	req, err := pageRequest(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	entries, err := h.ledgerUC.ListEntries(r.Context(), userID, req)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newPageResponse(entries, newLedgerEntryResponses),
+	})
+}
+
+type settleFunc func(ctx context.Context, userID int, amount int64, note string) (*domain.LedgerEntry, error)
+
+func (h *LedgerHandler) settle(w http.ResponseWriter, r *http.Request, fn settleFunc) {
+	userID, err := pathID(r, "userId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	amount, err := strconv.ParseInt(r.FormValue("amount"), 10, 64)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, invalidField("amount", "must be an integer"))
+		return
+	}
+
+	entry, err := fn(r.Context(), userID, amount, r.FormValue("note"))
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newLedgerEntryResponse(entry),
+	})
+}
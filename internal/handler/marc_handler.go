@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"library/internal/marc"
+	"library/internal/usecase"
+	"library/responder"
+	"net/http"
+)
+
+type MARCer interface {
+	ExportBook(w http.ResponseWriter, r *http.Request)
+	ExportBooks(w http.ResponseWriter, r *http.Request)
+	ImportRecords(w http.ResponseWriter, r *http.Request)
+}
+
+type MARCHandler struct {
+	marcUC    usecase.MARCer
+	responder responder.Responder
+}
+
+func NewMARCHandler(marcUC usecase.MARCer, responder responder.Responder) MARCer {
+	return &MARCHandler{
+		marcUC:    marcUC,
+		responder: responder,
+	}
+}
+
+// @Summary			export book as MARC
+// @Description		export one book as a MARC21 record: binary ISO 2709 (format=marc) or MARCXML (format=marcxml, default)
+// @Tags			book
+// @Produce			application/marcxml+xml
+// @Produce			application/marc
+// @Param			bookId   path	string	true  "id book"
+// @Param			format   query	string	false "record format" Enums(marcxml, marc)
+// @Success			200		{string}	string
+// @Failure			400		{object}	responder.Problem
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/book/{bookId}/marc [get]
+func (h *MARCHandler) ExportBook(w http.ResponseWriter, r *http.Request) {
+	bookID, err := pathID(r, "bookId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+	format, err := marcFormat(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	out := &streamWriter{ResponseWriter: w, contentType: format.ContentType()}
+	if err := h.marcUC.ExportBook(r.Context(), bookID, format, out); err != nil {
+		out.fail(h.responder, r, err)
+	}
+}
+
+// @Summary			export catalogue as MARC
+// @Description		stream the catalogue as MARC21 records; filter and sort work as in GET /book, limit and after are ignored
+// @Tags			book
+// @Produce			application/marcxml+xml
+// @Produce			application/marc
+// @Param			format   query	string	false "record format" Enums(marcxml, marc)
+// @Param			sort     query	string	false "sort field, prefix - for descending"
+// @Param			filter   query	[]string	false "field:operator:value" collectionFormat(multi)
+// @Success			200		{string}	string
+// @Failure			400		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/book/marc [get]
+func (h *MARCHandler) ExportBooks(w http.ResponseWriter, r *http.Request) {
+	req, err := pageRequest(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+	req.After = ""
+	format, err := marcFormat(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	extendDeadlines(w)
+	out := &streamWriter{ResponseWriter: w, contentType: format.ContentType()}
+	if err := h.marcUC.ExportBooks(r.Context(), req, format, out); err != nil {
+		out.fail(h.responder, r, err)
+	}
+}
+
+// @Summary			import MARC records
+// @Description		create books from a binary MARC21 or MARCXML file. Authors are matched by name or created, copies are not created.
+// @Description		Line in the report is the record number in the file.
+// @Tags			book
+// @Accept			application/marcxml+xml
+// @Accept			application/marc
+// @Produce			json
+// @Param			format   query	string	false "record format" Enums(marcxml, marc)
+// @Param			file     body	string	true  "MARC file"
+// @Success			200		{object}	Response{data=ImportReportResponse}
+// @Failure			400		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/book/marc [post]
+func (h *MARCHandler) ImportRecords(w http.ResponseWriter, r *http.Request) {
+	format, err := marcFormat(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	extendDeadlines(w)
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	report, err := h.marcUC.ImportRecords(r.Context(), format, body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.responder.ErrorBadRequest(w, r, invalidField("file", "must be at most 64 MB"))
+		return
+	}
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newImportReportResponse(report),
+	})
+}
+
+// marcFormat читает параметр format; по умолчанию MARCXML
+func marcFormat(r *http.Request) (marc.Format, error) {
+	format := marc.Format(r.URL.Query().Get("format"))
+	if format == "" {
+		return marc.MARCXML, nil
+	}
+	if !format.Valid() {
+		return "", invalidField("format", "must be one of [marcxml marc]")
+	}
+	return format, nil
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"library/internal/domain"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// pathID читает целочисленный параметр пути
+func pathID(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		return 0, invalidField(name, "must be an integer")
+	}
+	return id, nil
+}
+
+// pageRequest читает параметры списка: limit, after, sort (с префиксом - по убыванию)
+// и повторяемый filter вида поле:оператор:значение
+func pageRequest(r *http.Request) (domain.PageRequest, error) {
+	q := r.URL.Query()
+	req := domain.PageRequest{After: q.Get("after")}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > domain.MaxPageLimit {
+			return req, invalidField("limit", fmt.Sprintf("must be an integer between 1 and %d", domain.MaxPageLimit))
+		}
+		req.Limit = limit
+	}
+	if v := q.Get("sort"); v != "" {
+		req.Sort, req.Desc = strings.CutPrefix(v, "-")
+	}
+	for _, v := range q["filter"] {
+		field, rest, _ := strings.Cut(v, ":")
+		op, value, ok := strings.Cut(rest, ":")
+		if !ok || field == "" {
+			return req, invalidField("filter", "must be field:operator:value")
+		}
+		req.Filters = append(req.Filters, domain.Filter{Field: field, Op: op, Value: value})
+	}
+	return req, nil
+}
+
+func invalidField(field, message string) error {
+	return &domain.ErrInvalidFields{Fields: []domain.FieldError{{Field: field, Message: message}}}
+}
+
+// decodeBody читает JSON тело запроса; неизвестные поля считаются ошибкой, чтобы опечатки не терялись молча
+func decodeBody(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// replaceRequest читает тело запроса PUT с полным новым состоянием ресурса
+func replaceRequest[T any](r *http.Request) (T, error) {
+	var req T
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return req, err
+	}
+	return req, decodeBody(data, &req)
+}
+
+// patchRequest применяет тело запроса как JSON Merge Patch (RFC 7386) к текущему состоянию ресурса
+func patchRequest[T any](r *http.Request, current T) (T, error) {
+	var req T
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		return req, err
+	}
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return req, err
+	}
+	merged, err := mergePatch(doc, patch)
+	if err != nil {
+		return req, err
+	}
+	return req, decodeBody(merged, &req)
+}
+
+func mergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(target, p))
+}
+
+// mergeValue: объекты сливаются по ключам, null удаляет ключ, остальные значения заменяются целиком
+func mergeValue(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergeValue(t[k], v)
+	}
+	return t
+}
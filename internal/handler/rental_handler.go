@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"library/internal/auth"
 	"library/internal/facade"
 	"library/responder"
 	"net/http"
@@ -10,6 +11,10 @@ import (
 type Rentaler interface {
 	RentBook(w http.ResponseWriter, r *http.Request)
 	ReturnBook(w http.ResponseWriter, r *http.Request)
+	ReturnCopy(w http.ResponseWriter, r *http.Request)
+	RenewRental(w http.ResponseWriter, r *http.Request)
+	ListOverdue(w http.ResponseWriter, r *http.Request)
+	ListUserOverdue(w http.ResponseWriter, r *http.Request)
 }
 
 type RentalHandler struct {
@@ -31,23 +36,31 @@ func NewRentHandler(rentUC facade.Facader, responder responder.Responder) Rental
 // @Produce			json
 // @Param			bookId   path	string	true  "bookId"
 // @Param			userId   path	string	true  "userID"
-// @Success			200		{object}	Response
+// @Success			200		{object}	Response{data=Data}
+// @Failure			403		{object}	responder.Problem
+// @Failure			409		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/rental/{bookId}/{userId} [post]
 func (h *RentalHandler) RentBook(w http.ResponseWriter, r *http.Request) {
-	bookID, err := strconv.Atoi(r.PathValue("bookId"))
+	bookID, err := pathID(r, "bookId")
 	if err != nil {
-		h.responder.ErrorBadRequest(w, err)
+		h.responder.ErrorBadRequest(w, r, err)
 		return
 	}
 
-	userID, err := strconv.Atoi(r.PathValue("userId"))
+	userID, err := pathID(r, "userId")
 	if err != nil {
-		h.responder.ErrorBadRequest(w, err)
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if !auth.CanActFor(r.Context(), userID) {
+		h.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
 		return
 	}
 
 	if err := h.rentUC.RentBook(r.Context(), bookID, userID); err != nil {
-		h.responder.ErrorInternal(w, err)
+		h.responder.Error(w, r, err)
 		return
 	}
 
@@ -60,22 +73,84 @@ func (h *RentalHandler) RentBook(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary			return book
-// @Description		return book
+// @Description		return the active rental of a title; a reader returns their own copy, a librarian or service client passes userId.
+// @Description		With several copies of one title out, DELETE /rental/copy/{barcode} names the copy exactly
 // @Tags			rental
 // @Accept			json
 // @Produce			json
 // @Param			bookId   path	string	true  "bookId"
-// @Success			200		{object}	Response
+// @Param			userId   query	int		false "reader holding the copy, defaults to the caller"
+// @Success			200		{object}	Response{data=Data}
+// @Failure			400		{object}	responder.Problem
+// @Failure			403		{object}	responder.Problem
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/rental/{bookId} [delete]
 func (h *RentalHandler) ReturnBook(w http.ResponseWriter, r *http.Request) {
-	bookID, err := strconv.Atoi(r.PathValue("bookId"))
+	bookID, err := pathID(r, "bookId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	var userID int
+	if p, ok := auth.PrincipalFrom(r.Context()); ok {
+		userID = p.UserID
+	}
+	if v := r.URL.Query().Get("userId"); v != "" {
+		if userID, err = strconv.Atoi(v); err != nil {
+			h.responder.ErrorBadRequest(w, r, invalidField("userId", "must be an integer"))
+			return
+		}
+	}
+	if userID == 0 {
+		h.responder.ErrorBadRequest(w, r, invalidField("userId", "is required"))
+		return
+	}
+	if !auth.CanActFor(r.Context(), userID) {
+		h.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
+		return
+	}
+
+	if err := h.rentUC.ReturnBook(r.Context(), bookID, userID); err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data: Data{
+			Message: "book rental completed",
+		},
+	})
+}
+
+// @Summary			return copy
+// @Description		return a lent-out copy by its barcode; the copy goes to the next hold in the queue or back on the shelf
+// @Tags			rental
+// @Accept			json
+// @Produce			json
+// @Param			barcode   path	string	true  "copy barcode"
+// @Success			200		{object}	Response{data=Data}
+// @Failure			403		{object}	responder.Problem
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/rental/copy/{barcode} [delete]
+func (h *RentalHandler) ReturnCopy(w http.ResponseWriter, r *http.Request) {
+	barcode := r.PathValue("barcode")
+
+	rental, err := h.rentUC.GetCopyRental(r.Context(), barcode)
 	if err != nil {
-		http.Error(w, "Invalid book ID", http.StatusBadRequest)
+		h.responder.Error(w, r, err)
+		return
+	}
+	if !auth.CanActFor(r.Context(), rental.UserID) {
+		h.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
 		return
 	}
 
-	if err := h.rentUC.ReturnBook(r.Context(), bookID); err != nil {
-		h.responder.ErrorInternal(w, err)
+	if err := h.rentUC.ReturnCopy(r.Context(), barcode); err != nil {
+		h.responder.Error(w, r, err)
 		return
 	}
 
@@ -86,3 +161,118 @@ func (h *RentalHandler) ReturnBook(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 }
+
+// @Summary			renew rental
+// @Description		extend rental due date by the loan period
+// @Tags			rental
+// @Accept			json
+// @Produce			json
+// @Param			rentalId   path	string	true  "rentalId"
+// @Success			200		{object}	Response{data=RentalResponse}
+// @Failure			409		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/rental/{rentalId}/renew [post]
+func (h *RentalHandler) RenewRental(w http.ResponseWriter, r *http.Request) {
+	rentalID, err := pathID(r, "rentalId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	rental, err := h.rentUC.GetRental(r.Context(), rentalID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+	if !auth.CanActFor(r.Context(), rental.UserID) {
+		h.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
+		return
+	}
+
+	rental, err = h.rentUC.RenewRental(r.Context(), rentalID)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newRentalResponse(rental),
+	})
+}
+
+// @Summary			list overdue rentals
+// @Description		list rentals not returned by due date
+// @Tags			rental
+// @Accept			json
+// @Produce			json
+// @Param			limit   query	int	false  "page size, 1-100, default 20"
+// @Param			after   query	string	false  "next_cursor of the previous page"
+// @Param			sort   query	string	false  "id, due_date (default), rental_date, prefix - for descending"
+// @Param			filter   query	[]string	false  "field:op:value on book_id, user_id, due_date; ops eq, ne, lt, lte, gt, gte, like"	collectionFormat(multi)
+// @Success			200		{object}	Response{data=PageResponse{items=[]OverdueRentalResponse}}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/rental/overdue [get]
+func (h *RentalHandler) ListOverdue(w http.ResponseWriter, r *http.Request) {
+	req, err := pageRequest(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	rentals, err := h.rentUC.ListOverdue(r.Context(), req)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newPageResponse(rentals, newOverdueResponses),
+	})
+}
+
+// @Summary			list user overdue rentals
+// @Description		list user rentals not returned by due date
+// @Tags			rental
+// @Accept			json
+// @Produce			json
+// @Param			userId   path	string	true  "userId"
+// @Param			limit   query	int	false  "page size, 1-100, default 20"
+// @Param			after   query	string	false  "next_cursor of the previous page"
+// @Param			sort   query	string	false  "id, due_date (default), rental_date, prefix - for descending"
+// @Param			filter   query	[]string	false  "field:op:value on book_id, user_id, due_date; ops eq, ne, lt, lte, gt, gte, like"	collectionFormat(multi)
+// @Success			200		{object}	Response{data=PageResponse{items=[]OverdueRentalResponse}}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/user/{userId}/overdue [get]
+func (h *RentalHandler) ListUserOverdue(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathID(r, "userId")
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if !auth.CanActFor(r.Context(), userID) {
+		h.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
+		return
+	}
+
+	req, err := pageRequest(r)
+	if err != nil {
+		h.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	rentals, err := h.rentUC.ListUserOverdue(r.Context(), userID, req)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newPageResponse(rentals, newOverdueResponses),
+	})
+}
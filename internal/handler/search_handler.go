@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"library/internal/domain"
+	"library/internal/usecase"
+	"library/responder"
+	"net/http"
+	"strconv"
+)
+
+type Searcher interface {
+	Search(w http.ResponseWriter, r *http.Request)
+	Suggest(w http.ResponseWriter, r *http.Request)
+}
+
+type SearchHandler struct {
+	searchUC  usecase.Searcher
+	responder responder.Responder
+}
+
+func NewSearchHandler(searchUC usecase.Searcher, responder responder.Responder) Searcher {
+	return &SearchHandler{
+		searchUC:  searchUC,
+		responder: responder,
+	}
+}
+
+// @Summary			search catalogue
+// @Description		full-text search over book titles, author names and biographies, best matches first
+// @Tags			search
+// @Accept			json
+// @Produce			json
+// @Param			q   query	string	true  "search words, supports quoted phrases, or and -word"
+// @Param			lang   query	string	false  "stemming: english or russian, detected from the query by default"
+// @Param			limit   query	int	false  "max results, 1-100, default 20"
+// @Success			200		{object}	Response{data=[]SearchHitResponse}
+// @Failure			400		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/search [get]
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := domain.SearchQuery{
+		Text:     q.Get("q"),
+		Language: q.Get("lang"),
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			h.responder.ErrorBadRequest(w, r, invalidField("limit", "must be an integer"))
+			return
+		}
+		query.Limit = limit
+	}
+
+	hits, err := h.searchUC.Search(r.Context(), query)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newSearchHitResponses(hits),
+	})
+}
+
+// @Summary			suggest titles and authors
+// @Description		typo-tolerant autocomplete over book titles and author names
+// @Tags			search
+// @Accept			json
+// @Produce			json
+// @Param			prefix   query	string	true  "text typed so far"
+// @Param			limit   query	int	false  "max suggestions, 1-50, default 10"
+// @Success			200		{object}	Response{data=[]SuggestionResponse}
+// @Failure			400		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/suggest [get]
+func (h *SearchHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			h.responder.ErrorBadRequest(w, r, invalidField("limit", "must be an integer"))
+			return
+		}
+	}
+
+	suggestions, err := h.searchUC.Suggest(r.Context(), q.Get("prefix"), limit)
+	if err != nil {
+		h.responder.Error(w, r, err)
+		return
+	}
+
+	h.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newSuggestionResponses(suggestions),
+	})
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"library/responder"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// bulkDeadline - время на загрузку или выгрузку каталога целиком, дольше обычного таймаута сервера
+const bulkDeadline = 5 * time.Minute
+
+// extendDeadlines продлевает таймауты соединения для массовых загрузок и выгрузок
+func extendDeadlines(w http.ResponseWriter) {
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(bulkDeadline))
+	_ = rc.SetWriteDeadline(time.Now().Add(bulkDeadline))
+}
+
+// streamWriter - ответ, который пишется потоком. Заголовки отправляются с первыми байтами,
+// поэтому ошибку до них можно вернуть обычным ответом, а после - только оборвать соединение
+type streamWriter struct {
+	http.ResponseWriter
+	contentType string
+	// filename - имя файла для сохранения; пустое, если ответ показывается в браузере
+	filename string
+	started  bool
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if !s.started {
+		s.started = true
+		s.Header().Set("Content-Type", s.contentType)
+		if s.filename != "" {
+			s.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": s.filename}))
+		}
+	}
+	return s.ResponseWriter.Write(p)
+}
+
+func (s *streamWriter) fail(respond responder.Responder, r *http.Request, err error) {
+	if !s.started {
+		respond.Error(s.ResponseWriter, r, err)
+		return
+	}
+	// клиент увидит оборванный ответ вместо файла, который выглядит целым
+	panic(http.ErrAbortHandler)
+}
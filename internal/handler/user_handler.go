@@ -1,17 +1,19 @@
 package handler
 
 import (
+	"library/internal/auth"
 	"library/internal/domain"
 	"library/internal/usecase"
 	"library/responder"
 	"net/http"
-	"strconv"
 	"time"
 )
 
 type Userer interface {
 	Create(w http.ResponseWriter, r *http.Request)
 	GetByID(w http.ResponseWriter, r *http.Request)
+	UpdateUser(w http.ResponseWriter, r *http.Request)
+	PatchUser(w http.ResponseWriter, r *http.Request)
 	DeleteUser(w http.ResponseWriter, r *http.Request)
 	GetAll(w http.ResponseWriter, r *http.Request)
 }
@@ -35,7 +37,13 @@ func NewUserHandler(userUC usecase.Userer, responder responder.Responder) Userer
 // @Produce			json
 // @Param name   	formData	string	true  "name"
 // @Param email   	formData	string	true  "email"
-// @Success			200		{object}	Response
+// @Param birth_date   	formData	string	false  "birth date, YYYY-MM-DD"
+// @Param password   	formData	string	false  "password"
+// @Param role   	formData	string	false  "patron or librarian; only a librarian may create librarians"
+// @Success			200		{object}	Response{data=UserResponse}
+// @Failure			403		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/user [post]
 func (u *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	name := r.FormValue("name")
@@ -43,16 +51,30 @@ func (u *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	user := domain.User{
 		Name:      name,
 		Email:     email,
+		Role:      r.FormValue("role"),
+		Password:  r.FormValue("password"),
 		CreatedAt: time.Now(),
 	}
+	if b := r.FormValue("birth_date"); b != "" {
+		birthDate, err := time.Parse(time.DateOnly, b)
+		if err != nil {
+			u.responder.ErrorBadRequest(w, r, invalidField("birth_date", "must be a date in YYYY-MM-DD format"))
+			return
+		}
+		user.BirthDate = &birthDate
+	}
+	if user.Role != "" && user.Role != domain.RolePatron && !canAssignRole(r) {
+		u.responder.ErrorForbidden(w, r, auth.ErrForbiddenRole)
+		return
+	}
 	if err := u.userUC.CreateUser(r.Context(), &user); err != nil {
-		u.responder.ErrorInternal(w, err)
+		u.responder.Error(w, r, err)
 		return
 	}
 
 	u.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    user,
+		Data:    newUserResponse(&user),
 	})
 }
 
@@ -62,24 +84,31 @@ func (u *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Accept			json
 // @Produce			json
 // @Param			userId   path	string	true  "get user"
-// @Success			200		{object}	Response
+// @Success			200		{object}	Response{data=UserResponse}
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/user/{userId} [get]
 func (u *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	userID, err := strconv.Atoi(r.PathValue("userId"))
+	userID, err := pathID(r, "userId")
 	if err != nil {
-		u.responder.ErrorBadRequest(w, err)
+		u.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if !auth.CanActFor(r.Context(), userID) {
+		u.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
 		return
 	}
 
 	user, err := u.userUC.GetByIDUser(r.Context(), userID)
 	if err != nil {
-		u.responder.ErrorInternal(w, err)
+		u.responder.Error(w, r, err)
 		return
 	}
 
 	u.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    user,
+		Data:    newUserResponse(user),
 	})
 }
 
@@ -89,24 +118,26 @@ func (u *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 // @Accept			json
 // @Produce			json
 // @Param			userId   path	string	true  "id user"
-// @Success			200		{object}	Response
+// @Success			200		{object}	Response{data=Data}
+// @Failure			404		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/user/{userId} [delete]
 func (u *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
-	userID, err := strconv.Atoi(r.PathValue("userId"))
+	userID, err := pathID(r, "userId")
 	if err != nil {
-		u.responder.ErrorBadRequest(w, err)
+		u.responder.ErrorBadRequest(w, r, err)
 		return
 	}
 
 	err = u.userUC.DeleteUser(r.Context(), userID)
 	if err != nil {
-		u.responder.ErrorInternal(w, err)
+		u.responder.Error(w, r, err)
 		return
 	}
 
 	u.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    "user id delete",
+		Data:    Data{Message: "user has been deleted"},
 	})
 }
 
@@ -115,17 +146,120 @@ func (u *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 // @Tags			user
 // @Accept			json
 // @Produce			json
-// @Success			200		{object}	Response
+// @Param			limit   query	int	false  "page size, 1-100, default 20"
+// @Param			after   query	string	false  "next_cursor of the previous page"
+// @Param			sort   query	string	false  "id, name, email, created_at, prefix - for descending"
+// @Param			filter   query	[]string	false  "field:op:value on name, email, role, birth_date, created_at; ops eq, ne, lt, lte, gt, gte, like"	collectionFormat(multi)
+// @Success			200		{object}	Response{data=PageResponse{items=[]UserResponse}}
+// @Failure			400		{object}	responder.Problem
+// @Security		ApiKeyAuth
 // @Router			/user/all [get]
 func (u *UserHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	users, err := u.userUC.GetAllUsers(r.Context())
+	req, err := pageRequest(r)
+	if err != nil {
+		u.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	users, err := u.userUC.ListUsers(r.Context(), req)
 	if err != nil {
-		u.responder.ErrorBadRequest(w, err)
+		u.responder.Error(w, r, err)
 		return
 	}
 
 	u.responder.OutputJSON(w, Response{
 		Success: true,
-		Data:    users,
+		Data:    newPageResponse(users, newUserResponses),
+	})
+}
+
+// @Summary			replace user
+// @Description		replace user profile; only librarians may change roles, an omitted role is kept
+// @Tags			user
+// @Accept			json
+// @Produce			json
+// @Param			userId   path	string	true  "id user"
+// @Param			user   body	UpdateUserRequest	true  "user"
+// @Success			200		{object}	Response{data=UserResponse}
+// @Failure			404		{object}	responder.Problem
+// @Failure			409		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/user/{userId} [put]
+func (u *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	u.update(w, r, func(*domain.User) (UpdateUserRequest, error) {
+		return replaceRequest[UpdateUserRequest](r)
+	})
+}
+
+// @Summary			patch user
+// @Description		update user fields with a JSON Merge Patch document
+// @Tags			user
+// @Accept			json
+// @Produce			json
+// @Param			userId   path	string	true  "id user"
+// @Param			patch   body	UpdateUserRequest	true  "merge patch"
+// @Success			200		{object}	Response{data=UserResponse}
+// @Failure			404		{object}	responder.Problem
+// @Failure			409		{object}	responder.Problem
+// @Failure			422		{object}	responder.Problem
+// @Security		ApiKeyAuth
+// @Router			/user/{userId} [patch]
+func (u *UserHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	u.update(w, r, func(user *domain.User) (UpdateUserRequest, error) {
+		return patchRequest(r, newUpdateUserRequest(user))
 	})
 }
+
+func (u *UserHandler) update(w http.ResponseWriter, r *http.Request, read func(*domain.User) (UpdateUserRequest, error)) {
+	userID, err := pathID(r, "userId")
+	if err != nil {
+		u.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+
+	if !auth.CanActFor(r.Context(), userID) {
+		u.responder.ErrorForbidden(w, r, auth.ErrNotOwner)
+		return
+	}
+
+	user, err := u.userUC.GetByIDUser(r.Context(), userID)
+	if err != nil {
+		u.responder.Error(w, r, err)
+		return
+	}
+	role := user.Role
+
+	req, err := read(user)
+	if err != nil {
+		u.responder.ErrorBadRequest(w, r, err)
+		return
+	}
+	if err := req.apply(user); err != nil {
+		u.responder.Error(w, r, err)
+		return
+	}
+
+	// читатель может править свой профиль, но не свою роль
+	if user.Role != role && !canAssignRole(r) {
+		u.responder.ErrorForbidden(w, r, auth.ErrForbiddenRole)
+		return
+	}
+
+	if err := u.userUC.UpdateUser(r.Context(), user); err != nil {
+		u.responder.Error(w, r, err)
+		return
+	}
+
+	u.responder.OutputJSON(w, Response{
+		Success: true,
+		Data:    newUserResponse(user),
+	})
+}
+
+// canAssignRole - роли назначает только библиотекарь; машинному клиенту это не разрешено,
+// иначе API ключ со scope users выдавал бы права библиотекаря
+func canAssignRole(r *http.Request) bool {
+	principal, ok := auth.PrincipalFrom(r.Context())
+	return ok && principal.Role == domain.RoleLibrarian
+}
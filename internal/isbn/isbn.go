@@ -0,0 +1,106 @@
+// Package isbn проверяет контрольные цифры ISBN-10 и ISBN-13 и переводит номер из одной формы в другую.
+// В базе ISBN хранится в форме ISBN-13 без разделителей.
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrLength   = errors.New("must have 10 or 13 digits")
+	ErrPrefix   = errors.New("ISBN-13 must start with 978 or 979")
+	ErrChecksum = errors.New("check digit does not match")
+)
+
+// Normalize проверяет ISBN-10 или ISBN-13 с дефисами и пробелами и возвращает ISBN-13 из одних цифр
+func Normalize(s string) (string, error) {
+	digits := clean(s)
+	switch len(digits) {
+	case 10:
+		if !valid10(digits) {
+			return "", ErrChecksum
+		}
+		return with13Check("978" + digits[:9]), nil
+	case 13:
+		if !strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979") {
+			return "", ErrPrefix
+		}
+		if !valid13(digits) {
+			return "", ErrChecksum
+		}
+		return digits, nil
+	}
+	return "", ErrLength
+}
+
+// To10 - форма ISBN-10 для нормализованного ISBN-13; у номеров с префиксом 979 её нет
+func To10(isbn13 string) (string, bool) {
+	if len(isbn13) != 13 || !strings.HasPrefix(isbn13, "978") {
+		return "", false
+	}
+	body := isbn13[3:12]
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(body[i]-'0')
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return body + "X", true
+	}
+	return body + string(rune('0'+check)), true
+}
+
+// clean убирает разделители; X допустим только последним символом ISBN-10
+func clean(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		switch {
+		case r >= '0' && r <= '9', r == 'X':
+			b.WriteRune(r)
+		case r == '-' || r == ' ':
+		default:
+			return ""
+		}
+	}
+	return b.String()
+}
+
+func valid10(s string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		var d int
+		switch {
+		case s[i] == 'X' && i == 9:
+			d = 10
+		case s[i] >= '0' && s[i] <= '9':
+			d = int(s[i] - '0')
+		default:
+			return false
+		}
+		sum += (10 - i) * d
+	}
+	return sum%11 == 0
+}
+
+func valid13(s string) bool {
+	for i := 0; i < 13; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return with13Check(s[:12]) == s
+}
+
+// with13Check дописывает контрольную цифру ISBN-13 к первым двенадцати цифрам
+func with13Check(first12 string) string {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(first12[i]-'0')
+	}
+	return first12 + string(rune('0'+(10-sum%10)%10))
+}
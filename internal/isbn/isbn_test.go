@@ -0,0 +1,77 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+		err  error
+	}{
+		{"ISBN-10", "0261103202", "9780261103207", nil},
+		{"ISBN-13", "9780261103207", "9780261103207", nil},
+		{"ISBN-13 with 979", "9791090636071", "9791090636071", nil},
+		{"X check digit", "080442957X", "9780804429573", nil},
+		{"lowercase x check digit", "080442957x", "9780804429573", nil},
+		{"hyphens", "0-261-10320-2", "9780261103207", nil},
+		{"spaces", "978 0 261 10320 7", "9780261103207", nil},
+		{"hyphens and spaces", "979-10-90636 07-1", "9791090636071", nil},
+		{"ISBN-10 bad checksum", "0261103203", "", ErrChecksum},
+		{"ISBN-13 bad checksum", "9780261103208", "", ErrChecksum},
+		{"X not last", "02611032X2", "", ErrChecksum},
+		{"X in ISBN-13", "978026110320X", "", ErrChecksum},
+		{"bad prefix", "9770261103207", "", ErrPrefix},
+		{"too short", "026110320", "", ErrLength},
+		{"twelve digits", "978026110320", "", ErrLength},
+		{"empty", "", "", ErrLength},
+		{"letters", "0261103202a", "", ErrLength},
+		{"other separators", "0.261.10320.2", "", ErrLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.in)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Fatalf("Normalize(%q) = %q, %v, want %q, %v", tt.in, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestTo10(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"9780261103207", "0261103202", true},
+		{"9780804429573", "080442957X", true},
+		// у номеров 979 нет формы ISBN-10
+		{"9791090636071", "", false},
+		{"9790000000001", "", false},
+		{"0261103202", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := To10(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("To10(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestRoundTrip - ISBN-10 переживает перевод в ISBN-13 и обратно
+func TestRoundTrip(t *testing.T) {
+	for _, isbn10 := range []string{"0261103202", "080442957X", "0152038655"} {
+		isbn13, err := Normalize(isbn10)
+		if err != nil {
+			t.Fatalf("Normalize(%q): %v", isbn10, err)
+		}
+		if back, ok := To10(isbn13); !ok || back != isbn10 {
+			t.Errorf("To10(Normalize(%q)) = %q, %v", isbn10, back, ok)
+		}
+	}
+}
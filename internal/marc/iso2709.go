@@ -0,0 +1,242 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// Разделители и размеры структуры записи ISO 2709
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+
+	leaderLength    = 24
+	entryLength     = 12
+	maxFieldLength  = 9999
+	maxRecordLength = 99999
+)
+
+// Reader - последовательное чтение двоичного файла MARC21. Записи делятся по терминатору записи,
+// поэтому после испорченной записи чтение продолжается со следующей
+type Reader struct {
+	r *bufio.Reader
+	n int
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+func (r *Reader) Read() (*Record, error) {
+	if err := r.skipSpace(); err != nil {
+		return nil, err
+	}
+
+	data, err := r.r.ReadBytes(recordTerminator)
+	if errors.Is(err, io.EOF) {
+		r.n++
+		return nil, &RecordError{Record: r.n, Err: fmt.Errorf("%w: missing record terminator", ErrFormat)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.n++
+	rec, err := Unmarshal(data)
+	if err != nil {
+		return nil, &RecordError{Record: r.n, Err: err}
+	}
+	return rec, nil
+}
+
+// skipSpace пропускает переводы строк между записями, которые оставляют некоторые выгрузки
+func (r *Reader) skipSpace() error {
+	for {
+		b, err := r.r.Peek(1)
+		if err != nil {
+			return err
+		}
+		switch b[0] {
+		case '\n', '\r', ' ', '\t':
+			_, _ = r.r.ReadByte()
+		default:
+			return nil
+		}
+	}
+}
+
+// Unmarshal разбирает одну запись ISO 2709 вместе с терминатором.
+// Длина из маркера не сверяется: её часто портят перекодировкой, а границы записи задаёт терминатор.
+func Unmarshal(data []byte) (*Record, error) {
+	data = bytes.TrimSuffix(data, []byte{recordTerminator})
+	if len(data) < leaderLength+1 {
+		return nil, fmt.Errorf("%w: record is shorter than its leader", ErrFormat)
+	}
+
+	if data[9] == 'a' {
+		if !utf8.Valid(data) {
+			return nil, fmt.Errorf("%w: invalid UTF-8", ErrFormat)
+		}
+	} else if !isASCII(data) {
+		// MARC-8 совпадает с ASCII только на латинице без диакритики
+		return nil, ErrEncoding
+	}
+
+	base, ok := number(data[12:17])
+	if !ok || base <= leaderLength || base > len(data) || data[base-1] != fieldTerminator {
+		return nil, fmt.Errorf("%w: invalid base address %q", ErrFormat, data[12:17])
+	}
+	directory := data[leaderLength : base-1]
+	if len(directory)%entryLength != 0 {
+		return nil, fmt.Errorf("%w: directory length %d is not a multiple of %d", ErrFormat, len(directory), entryLength)
+	}
+
+	rec := &Record{Leader: string(data[:leaderLength])}
+	for i := 0; i < len(directory); i += entryLength {
+		entry := directory[i : i+entryLength]
+		tag := string(entry[:3])
+		length, okLen := number(entry[3:7])
+		start, okStart := number(entry[7:12])
+		if !okLen || !okStart || start > len(data)-base || start+length > len(data)-base {
+			return nil, fmt.Errorf("%w: invalid directory entry %q", ErrFormat, entry)
+		}
+		field := bytes.TrimSuffix(data[base+start:base+start+length], []byte{fieldTerminator})
+
+		if isControlTag(tag) {
+			rec.AddControl(tag, string(field))
+			continue
+		}
+		if len(field) < 2 {
+			return nil, fmt.Errorf("%w: field %s has no indicators", ErrFormat, tag)
+		}
+		df := DataField{Tag: tag, Ind1: field[0], Ind2: field[1]}
+		// всё до первого разделителя подполя не принадлежит ни одному подполю и отбрасывается
+		for _, sf := range bytes.Split(field[2:], []byte{subfieldDelimiter})[1:] {
+			if len(sf) == 0 {
+				continue
+			}
+			df.Subfields = append(df.Subfields, Subfield{Code: sf[0], Value: string(sf[1:])})
+		}
+		rec.Fields = append(rec.Fields, df)
+	}
+	return rec, nil
+}
+
+// Marshal собирает запись ISO 2709 в кодировке UTF-8. Поля пишутся в порядке записи:
+// сначала управляющие, затем поля данных
+func Marshal(rec *Record) ([]byte, error) {
+	var directory, body bytes.Buffer
+	add := func(tag string, field []byte) error {
+		if len(tag) != 3 {
+			return fmt.Errorf("%w: invalid tag %q", ErrFormat, tag)
+		}
+		if bytes.ContainsAny(field, "\x1d\x1e") {
+			return fmt.Errorf("%w: field %s contains a MARC delimiter", ErrFormat, tag)
+		}
+		field = append(field, fieldTerminator)
+		if len(field) > maxFieldLength {
+			return fmt.Errorf("%w: field %s is %d bytes long", ErrTooLong, tag, len(field))
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", tag, len(field), body.Len())
+		body.Write(field)
+		return nil
+	}
+
+	for _, f := range rec.Controls {
+		if err := add(f.Tag, []byte(f.Value)); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range rec.Fields {
+		field := []byte{indicator(f.Ind1), indicator(f.Ind2)}
+		for _, sf := range f.Subfields {
+			if sf.Code == subfieldDelimiter || bytes.IndexByte([]byte(sf.Value), subfieldDelimiter) >= 0 {
+				return nil, fmt.Errorf("%w: field %s contains a MARC delimiter", ErrFormat, f.Tag)
+			}
+			field = append(field, subfieldDelimiter, sf.Code)
+			field = append(field, sf.Value...)
+		}
+		if err := add(f.Tag, field); err != nil {
+			return nil, err
+		}
+	}
+
+	base := leaderLength + directory.Len() + 1
+	total := base + body.Len() + 1
+	if total > maxRecordLength {
+		return nil, fmt.Errorf("%w: record is %d bytes long", ErrTooLong, total)
+	}
+
+	leader := []byte(MonographLeader)
+	if len(rec.Leader) == leaderLength {
+		leader = []byte(rec.Leader)
+	}
+	copy(leader[0:5], fmt.Sprintf("%05d", total))
+	leader[9] = 'a'
+	leader[10], leader[11] = '2', '2'
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+	copy(leader[20:24], "4500")
+
+	out := make([]byte, 0, total)
+	out = append(out, leader...)
+	out = append(out, directory.Bytes()...)
+	out = append(out, fieldTerminator)
+	out = append(out, body.Bytes()...)
+	out = append(out, recordTerminator)
+	return out, nil
+}
+
+// Writer - запись двоичного файла MARC21, записи идут подряд без разделителей
+type Writer struct {
+	w *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (w *Writer) Write(rec *Record) error {
+	data, err := Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *Writer) Close() error {
+	return w.w.Flush()
+}
+
+func indicator(b byte) byte {
+	if b == 0 {
+		return ' '
+	}
+	return b
+}
+
+// number разбирает числовое поле маркера или справочника: только цифры ASCII, без знака и пробелов
+func number(b []byte) (int, bool) {
+	n := 0
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, len(b) > 0
+}
+
+func isASCII(data []byte) bool {
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
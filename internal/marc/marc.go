@@ -0,0 +1,155 @@
+// Package marc читает и пишет библиографические записи MARC21 в двоичном виде (ISO 2709) и в MARCXML.
+// Пакет ничего не знает о каталоге: перевод записей в книги и обратно делает usecase.
+package marc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrFormat   = errors.New("malformed MARC record")
+	ErrEncoding = errors.New("only UTF-8 MARC records are supported")
+	ErrTooLong  = errors.New("MARC record does not fit ISO 2709 limits")
+)
+
+// MonographLeader - маркер записи об отдельном издании: текст, монография, кодировка UTF-8,
+// пунктуация между подполями не ставится. Длина записи и базовый адрес проставляются при записи в ISO 2709.
+const MonographLeader = "00000nam a2200000 n 4500"
+
+// Record - запись MARC: маркер, управляющие поля 00X и поля данных с индикаторами и подполями
+type Record struct {
+	Leader   string
+	Controls []ControlField
+	Fields   []DataField
+}
+
+type ControlField struct {
+	Tag   string
+	Value string
+}
+
+type DataField struct {
+	Tag       string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// RecordError - ошибка одной записи файла; чтение можно продолжить со следующей записи
+type RecordError struct {
+	Record int
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Record, e.Err)
+}
+
+func (e *RecordError) Unwrap() error { return e.Err }
+
+// Control - значение управляющего поля, пустое если поля нет
+func (r *Record) Control(tag string) string {
+	for _, f := range r.Controls {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Values - значения подполя code во всех полях tag в порядке записи
+func (r *Record) Values(tag string, code byte) []string {
+	var values []string
+	for _, f := range r.Fields {
+		if f.Tag != tag {
+			continue
+		}
+		for _, sf := range f.Subfields {
+			if sf.Code == code {
+				values = append(values, sf.Value)
+			}
+		}
+	}
+	return values
+}
+
+// Value - первое значение подполя, пустое если его нет
+func (r *Record) Value(tag string, code byte) string {
+	if values := r.Values(tag, code); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Punctuated - может ли запись содержать пунктуацию между подполями. Позиция 18 маркера «c» или «n»
+// означает, что пунктуация опущена и значения подполей можно брать как есть
+func (r *Record) Punctuated() bool {
+	if len(r.Leader) != leaderLength {
+		return true
+	}
+	return r.Leader[18] != 'c' && r.Leader[18] != 'n'
+}
+
+func (r *Record) AddControl(tag, value string) {
+	r.Controls = append(r.Controls, ControlField{Tag: tag, Value: value})
+}
+
+// AddField добавляет поле данных; пробел в индикаторе означает «не определён»
+func (r *Record) AddField(tag string, ind1, ind2 byte, subfields ...Subfield) {
+	r.Fields = append(r.Fields, DataField{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: subfields})
+}
+
+func isControlTag(tag string) bool {
+	return len(tag) == 3 && tag[0] == '0' && tag[1] == '0'
+}
+
+type RecordReader interface {
+	// Read возвращает следующую запись, io.EOF в конце файла и *RecordError для испорченной записи
+	Read() (*Record, error)
+}
+
+type RecordWriter interface {
+	Write(rec *Record) error
+	// Close дописывает хвост файла; поток под писателем не закрывается
+	Close() error
+}
+
+// Format - формат файла записей
+type Format string
+
+const (
+	MARC21  Format = "marc"
+	MARCXML Format = "marcxml"
+)
+
+func (f Format) Valid() bool {
+	return f == MARC21 || f == MARCXML
+}
+
+func (f Format) ContentType() string {
+	if f == MARC21 {
+		return "application/marc"
+	}
+	return "application/marcxml+xml"
+}
+
+func (f Format) NewReader(r io.Reader) RecordReader {
+	if f == MARC21 {
+		return NewReader(r)
+	}
+	return NewXMLReader(r)
+}
+
+func (f Format) NewWriter(w io.Writer) RecordWriter {
+	if f == MARC21 {
+		return NewWriter(w)
+	}
+	return NewXMLWriter(w)
+}
@@ -0,0 +1,250 @@
+package marc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, r RecordReader) []*Record {
+	t.Helper()
+	var records []*Record
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("read record %d: %v", len(records)+1, err)
+		}
+		records = append(records, rec)
+	}
+}
+
+func writeAll(t *testing.T, format Format, records []*Record) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := format.NewWriter(&buf)
+	for _, rec := range records {
+		if err := w.Write(rec); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// withoutLayout - маркер без длины записи, базового адреса и кодировки, которые Marshal проставляет сам
+func withoutLayout(records []*Record) []*Record {
+	out := make([]*Record, len(records))
+	for i, rec := range records {
+		c := *rec
+		leader := []byte(c.Leader)
+		copy(leader[0:5], "00000")
+		copy(leader[12:17], "00000")
+		leader[9] = 'a'
+		c.Leader = string(leader)
+		out[i] = &c
+	}
+	return out
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		format  Format
+		count   int
+	}{
+		{"MARCXML", "records.xml", MARCXML, 3},
+		{"MARC21", "records.mrc", MARC21, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded := readAll(t, tt.format.NewReader(bytes.NewReader(fixture(t, tt.fixture))))
+			if len(decoded) != tt.count {
+				t.Fatalf("decoded %d records, want %d", len(decoded), tt.count)
+			}
+
+			encoded := writeAll(t, tt.format, decoded)
+			again := readAll(t, tt.format.NewReader(bytes.NewReader(encoded)))
+			if !reflect.DeepEqual(again, decoded) {
+				t.Fatalf("records changed after encode and decode:\n got %+v\nwant %+v", again, decoded)
+			}
+		})
+	}
+}
+
+func TestMARC21FixtureIsStable(t *testing.T) {
+	data := fixture(t, "records.mrc")
+	encoded := writeAll(t, MARC21, readAll(t, NewReader(bytes.NewReader(data))))
+	if !bytes.Equal(encoded, data) {
+		t.Fatal("re-encoded MARC21 differs from the fixture")
+	}
+}
+
+func TestCrossFormat(t *testing.T) {
+	fromXML := readAll(t, NewXMLReader(bytes.NewReader(fixture(t, "records.xml"))))
+	fromMARC := readAll(t, NewReader(bytes.NewReader(fixture(t, "records.mrc"))))
+	if !reflect.DeepEqual(withoutLayout(fromMARC), withoutLayout(fromXML)) {
+		t.Fatal("MARC21 and MARCXML fixtures decode to different records")
+	}
+
+	viaMARC := readAll(t, NewReader(bytes.NewReader(writeAll(t, MARC21, fromXML))))
+	viaXML := readAll(t, NewXMLReader(bytes.NewReader(writeAll(t, MARCXML, viaMARC))))
+	if !reflect.DeepEqual(viaXML, viaMARC) {
+		t.Fatal("records changed after MARC21 -> MARCXML -> MARC21")
+	}
+}
+
+func TestFixtureValues(t *testing.T) {
+	records := readAll(t, NewXMLReader(bytes.NewReader(fixture(t, "records.xml"))))
+	rec := records[1]
+	if got := rec.Value("245", 'b'); got != "роман-эпопея /" {
+		t.Errorf("245 $b = %q", got)
+	}
+	if got := rec.Values("020", 'a'); len(got) != 1 {
+		t.Errorf("020 $a = %q, want one value", got)
+	}
+	if got := rec.Control("008"); len(got) != 40 {
+		t.Errorf("008 has %d characters, want 40", len(got))
+	}
+	if rec.Punctuated() != true {
+		t.Error("record with leader/18 = i must be punctuated")
+	}
+}
+
+// record собирает запись ISO 2709 из маркера, справочника и области данных без проверок
+func record(leader, directory, data string) []byte {
+	return []byte(leader + directory + "\x1e" + data + "\x1d")
+}
+
+func TestUnmarshalMalformed(t *testing.T) {
+	const data = "12345\x1e  \x1faTitle\x1e"
+	tests := []struct {
+		name string
+		raw  []byte
+		want error
+	}{
+		{"shorter than leader", []byte("00012nam\x1d"), ErrFormat},
+		{"non-digit base address", record("00000nam a22ab000 n 4500", "001000600000", data), ErrFormat},
+		{"signed base address", record("00000nam a22-0037 n 4500", "001000600000", data), ErrFormat},
+		{"base address inside leader", record("00000nam a2200010 n 4500", "001000600000", data), ErrFormat},
+		{"base address past end", record("00000nam a2299999 n 4500", "001000600000", data), ErrFormat},
+		{"base address not after directory", record("00000nam a2200036 n 4500", "001000600000", data), ErrFormat},
+		{"directory not multiple of 12", record("00000nam a2200042 n 4500", "00100060000024500", data), ErrFormat},
+		{"negative length", record("00000nam a2200049 n 4500", "001000600000245-00100006", data), ErrFormat},
+		{"length with space", record("00000nam a2200049 n 4500", "001000600000245 01200006", data), ErrFormat},
+		{"start past end", record("00000nam a2200049 n 4500", "001000600000245001299999", data), ErrFormat},
+		{"field past end", record("00000nam a2200049 n 4500", "001000600000245009900006", data), ErrFormat},
+		{"data field without indicators", record("00000nam a2200049 n 4500", "001000600000245000100006", data), ErrFormat},
+		{"invalid UTF-8", record("00000nam a2200037 n 4500", "001000300000", "\xff\xfe\x1e"), ErrFormat},
+		{"MARC-8 with non-ASCII bytes", record("00000nam  2200037 n 4500", "001000300000", "\xe2\x80\x1e"), ErrEncoding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Unmarshal(tt.raw)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Unmarshal() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalHandBuilt(t *testing.T) {
+	raw := record("00000nam a2200049 n 4500", "001000600000245001000006", "12345\x1e10\x1faTitle\x1e")
+	rec, err := Unmarshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Control("001") != "12345" || rec.Value("245", 'a') != "Title" || rec.Fields[0].Ind1 != '1' {
+		t.Fatalf("unexpected record %+v", rec)
+	}
+}
+
+func TestReaderContinuesAfterBadRecord(t *testing.T) {
+	good := fixture(t, "records.mrc")
+	first := good[:bytes.IndexByte(good, recordTerminator)+1]
+
+	var stream []byte
+	stream = append(stream, first...)
+	stream = append(stream, '\n')
+	stream = append(stream, record("00000nam a2200061 n 4500", "245-00100000", "x")...)
+	stream = append(stream, first...)
+	stream = append(stream, first[:100]...)
+
+	r := NewReader(bytes.NewReader(stream))
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("record 1: %v", err)
+	}
+	var recErr *RecordError
+	if _, err := r.Read(); !errors.As(err, &recErr) || recErr.Record != 2 || !errors.Is(err, ErrFormat) {
+		t.Fatalf("record 2: error = %v, want RecordError for record 2", err)
+	}
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("record 3: %v", err)
+	}
+	if _, err := r.Read(); !errors.As(err, &recErr) || recErr.Record != 4 {
+		t.Fatalf("truncated record 4: error = %v, want RecordError", err)
+	}
+	if _, err := r.Read(); !errors.Is(err, io.EOF) {
+		t.Fatalf("after last record: error = %v, want io.EOF", err)
+	}
+}
+
+func TestXMLReaderMalformed(t *testing.T) {
+	tests := []struct {
+		name      string
+		doc       string
+		recordErr bool
+	}{
+		{"unclosed element", `<collection><record><leader>x</leader>`, false},
+		{"mismatched tags", `<record><leader>x</record>`, false},
+		{"long indicator", `<record><datafield tag="245" ind1="10" ind2=" "/></record>`, true},
+		{"bad tag", `<record><controlfield tag="1">x</controlfield></record>`, true},
+		{"bad subfield code", `<record><datafield tag="245" ind1=" " ind2=" "><subfield code="ab">x</subfield></datafield></record>`, true},
+		{"non UTF-8 charset", `<?xml version="1.0" encoding="ISO-8859-1"?><record/>`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewXMLReader(strings.NewReader(tt.doc)).Read()
+			var recErr *RecordError
+			if tt.recordErr && !errors.As(err, &recErr) {
+				t.Fatalf("error = %v, want RecordError", err)
+			}
+			if !errors.Is(err, ErrFormat) && !errors.Is(err, ErrEncoding) {
+				t.Fatalf("error = %v, want ErrFormat or ErrEncoding", err)
+			}
+		})
+	}
+}
+
+func TestMarshalRejects(t *testing.T) {
+	long := &Record{}
+	long.AddField("500", ' ', ' ', Subfield{Code: 'a', Value: strings.Repeat("x", maxFieldLength)})
+	delimiter := &Record{}
+	delimiter.AddField("245", ' ', ' ', Subfield{Code: 'a', Value: "a\x1fb"})
+	badTag := &Record{}
+	badTag.AddControl("1", "x")
+
+	for name, rec := range map[string]*Record{"long field": long, "delimiter in value": delimiter, "bad tag": badTag} {
+		if _, err := Marshal(rec); err == nil {
+			t.Errorf("%s: Marshal() succeeded, want error", name)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package marc
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Namespace - пространство имён MARCXML (MARC21 slim)
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlRecord struct {
+	XMLName  xml.Name       `xml:"record"`
+	Leader   string         `xml:"leader"`
+	Controls []xmlControl   `xml:"controlfield"`
+	Fields   []xmlDataField `xml:"datafield"`
+}
+
+type xmlControl struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+func newXMLRecord(rec *Record) xmlRecord {
+	x := xmlRecord{Leader: rec.Leader}
+	if len(x.Leader) != leaderLength {
+		x.Leader = MonographLeader
+	}
+	for _, f := range rec.Controls {
+		x.Controls = append(x.Controls, xmlControl(f))
+	}
+	for _, f := range rec.Fields {
+		df := xmlDataField{Tag: f.Tag, Ind1: string(indicator(f.Ind1)), Ind2: string(indicator(f.Ind2))}
+		for _, sf := range f.Subfields {
+			df.Subfields = append(df.Subfields, xmlSubfield{Code: string(sf.Code), Value: sf.Value})
+		}
+		x.Fields = append(x.Fields, df)
+	}
+	return x
+}
+
+func (x xmlRecord) record() (*Record, error) {
+	rec := &Record{Leader: x.Leader}
+	for _, f := range x.Controls {
+		if len(f.Tag) != 3 {
+			return nil, fmt.Errorf("%w: invalid tag %q", ErrFormat, f.Tag)
+		}
+		rec.AddControl(f.Tag, f.Value)
+	}
+	for _, f := range x.Fields {
+		if len(f.Tag) != 3 {
+			return nil, fmt.Errorf("%w: invalid tag %q", ErrFormat, f.Tag)
+		}
+		ind1, ok1 := xmlIndicator(f.Ind1)
+		ind2, ok2 := xmlIndicator(f.Ind2)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("%w: field %s has invalid indicators", ErrFormat, f.Tag)
+		}
+		df := DataField{Tag: f.Tag, Ind1: ind1, Ind2: ind2}
+		for _, sf := range f.Subfields {
+			if len(sf.Code) != 1 {
+				return nil, fmt.Errorf("%w: field %s has invalid subfield code %q", ErrFormat, f.Tag, sf.Code)
+			}
+			df.Subfields = append(df.Subfields, Subfield{Code: sf.Code[0], Value: sf.Value})
+		}
+		rec.Fields = append(rec.Fields, df)
+	}
+	return rec, nil
+}
+
+func xmlIndicator(s string) (byte, bool) {
+	switch len(s) {
+	case 0:
+		return ' ', true
+	case 1:
+		return s[0], true
+	default:
+		return 0, false
+	}
+}
+
+// XMLReader - потоковое чтение MARCXML: читает и <collection>, и одиночный <record>, префикс пространства имён не важен
+type XMLReader struct {
+	d *xml.Decoder
+	n int
+}
+
+func NewXMLReader(r io.Reader) *XMLReader {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return nil, ErrEncoding
+	}
+	return &XMLReader{d: d}
+}
+
+func (r *XMLReader) Read() (*Record, error) {
+	for {
+		tok, err := r.d.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, xmlError(err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var x xmlRecord
+		if err := r.d.DecodeElement(&x, &start); err != nil {
+			return nil, xmlError(err)
+		}
+		r.n++
+		rec, err := x.record()
+		if err != nil {
+			return nil, &RecordError{Record: r.n, Err: err}
+		}
+		return rec, nil
+	}
+}
+
+// xmlError - синтаксическая ошибка XML; после неё документ дальше не читается
+func xmlError(err error) error {
+	var syntaxErr *xml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return err
+}
+
+// XMLWriter - потоковая запись MARCXML: записи внутри одного <collection>
+type XMLWriter struct {
+	w       *bufio.Writer
+	enc     *xml.Encoder
+	started bool
+}
+
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	bw := bufio.NewWriter(w)
+	return &XMLWriter{w: bw, enc: xml.NewEncoder(bw)}
+}
+
+func (w *XMLWriter) Write(rec *Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.enc.Encode(newXMLRecord(rec)); err != nil {
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+// Close закрывает <collection>; пустой экспорт даёт пустую коллекцию
+func (w *XMLWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString("</collection>\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *XMLWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	_, err := w.w.WriteString(xml.Header + `<collection xmlns="` + Namespace + `">` + "\n")
+	return err
+}
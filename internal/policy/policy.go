@@ -0,0 +1,224 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"library/internal/domain"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	CodeUserBlocked       = "user_blocked"
+	CodeMaxLoansPerUser   = "max_loans_per_user"
+	CodeMaxLoansPerAuthor = "max_loans_per_author"
+	CodeMaxLoansPerGenre  = "max_loans_per_genre"
+	CodeAgeRestricted     = "age_restricted"
+	CodeAgeUnknown        = "age_unknown"
+)
+
+// Config - правила выдачи из файла политики; нулевой лимит отключает правило
+type Config struct {
+	MaxLoansPerUser   int              `json:"max_loans_per_user"`
+	MaxLoansPerAuthor int              `json:"max_loans_per_author"`
+	MaxLoansPerGenre  int              `json:"max_loans_per_genre"`
+	BlockedUsers      []int            `json:"blocked_users"`
+	AgeRestrictions   []AgeRestriction `json:"age_restrictions"`
+}
+
+type AgeRestriction struct {
+	Genre  string `json:"genre"`
+	MinAge int    `json:"min_age"`
+}
+
+// Checkout - сведения о запрошенной выдаче
+type Checkout struct {
+	User  *domain.User
+	Book  *domain.Book
+	Loans []domain.Book
+	Now   time.Time
+}
+
+// Rule - отдельное правило выдачи, nil означает что правило не нарушено
+type Rule interface {
+	Evaluate(c Checkout) *domain.PolicyViolation
+}
+
+type Evaluator interface {
+	Evaluate(c Checkout) []domain.PolicyViolation
+}
+
+type Engine struct {
+	rules []Rule
+}
+
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// NewEngineFromConfig собирает движок из правил, включённых в конфигурации
+func NewEngineFromConfig(conf *Config) *Engine {
+	var rules []Rule
+	if len(conf.BlockedUsers) > 0 {
+		rules = append(rules, NewBlockedUsers(conf.BlockedUsers))
+	}
+	if conf.MaxLoansPerUser > 0 {
+		rules = append(rules, MaxLoans{Limit: conf.MaxLoansPerUser})
+	}
+	if conf.MaxLoansPerAuthor > 0 {
+		rules = append(rules, MaxLoansPerAuthor{Limit: conf.MaxLoansPerAuthor})
+	}
+	if conf.MaxLoansPerGenre > 0 {
+		rules = append(rules, MaxLoansPerGenre{Limit: conf.MaxLoansPerGenre})
+	}
+	if len(conf.AgeRestrictions) > 0 {
+		rules = append(rules, NewAgeRestrictions(conf.AgeRestrictions))
+	}
+	return NewEngine(rules...)
+}
+
+// Evaluate проверяет все правила и возвращает все нарушения сразу
+func (e *Engine) Evaluate(c Checkout) []domain.PolicyViolation {
+	var violations []domain.PolicyViolation
+	for _, rule := range e.rules {
+		if v := rule.Evaluate(c); v != nil {
+			violations = append(violations, *v)
+		}
+	}
+	return violations
+}
+
+// LoadConfig читает файл политики; если файла нет, ограничения не применяются
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var conf Config
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return &conf, nil
+}
+
+type BlockedUsers struct {
+	ids map[int]struct{}
+}
+
+func NewBlockedUsers(ids []int) BlockedUsers {
+	set := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return BlockedUsers{ids: set}
+}
+
+func (r BlockedUsers) Evaluate(c Checkout) *domain.PolicyViolation {
+	if _, ok := r.ids[c.User.ID]; !ok {
+		return nil
+	}
+	return &domain.PolicyViolation{Code: CodeUserBlocked, Message: "user is blocked from borrowing"}
+}
+
+type MaxLoans struct {
+	Limit int
+}
+
+func (r MaxLoans) Evaluate(c Checkout) *domain.PolicyViolation {
+	if len(c.Loans) < r.Limit {
+		return nil
+	}
+	return &domain.PolicyViolation{
+		Code:    CodeMaxLoansPerUser,
+		Message: fmt.Sprintf("user already has %d of %d allowed loans", len(c.Loans), r.Limit),
+	}
+}
+
+type MaxLoansPerAuthor struct {
+	Limit int
+}
+
+func (r MaxLoansPerAuthor) Evaluate(c Checkout) *domain.PolicyViolation {
+	count := 0
+	for _, loan := range c.Loans {
+		if loan.AuthorID == c.Book.AuthorID {
+			count++
+		}
+	}
+	if count < r.Limit {
+		return nil
+	}
+	return &domain.PolicyViolation{
+		Code:    CodeMaxLoansPerAuthor,
+		Message: fmt.Sprintf("user already has %d of %d allowed loans by this author", count, r.Limit),
+	}
+}
+
+type MaxLoansPerGenre struct {
+	Limit int
+}
+
+func (r MaxLoansPerGenre) Evaluate(c Checkout) *domain.PolicyViolation {
+	if c.Book.Genre == "" {
+		return nil
+	}
+	count := 0
+	for _, loan := range c.Loans {
+		if strings.EqualFold(loan.Genre, c.Book.Genre) {
+			count++
+		}
+	}
+	if count < r.Limit {
+		return nil
+	}
+	return &domain.PolicyViolation{
+		Code:    CodeMaxLoansPerGenre,
+		Message: fmt.Sprintf("user already has %d of %d allowed loans in genre %s", count, r.Limit, c.Book.Genre),
+	}
+}
+
+type AgeRestrictions struct {
+	minAge map[string]int
+}
+
+func NewAgeRestrictions(restrictions []AgeRestriction) AgeRestrictions {
+	minAge := make(map[string]int, len(restrictions))
+	for _, r := range restrictions {
+		minAge[strings.ToLower(r.Genre)] = r.MinAge
+	}
+	return AgeRestrictions{minAge: minAge}
+}
+
+func (r AgeRestrictions) Evaluate(c Checkout) *domain.PolicyViolation {
+	minAge, ok := r.minAge[strings.ToLower(c.Book.Genre)]
+	if !ok {
+		return nil
+	}
+	if c.User.BirthDate == nil {
+		return &domain.PolicyViolation{
+			Code:    CodeAgeUnknown,
+			Message: fmt.Sprintf("genre %s requires age %d+, user birth date is unknown", c.Book.Genre, minAge),
+		}
+	}
+	if age(*c.User.BirthDate, c.Now) >= minAge {
+		return nil
+	}
+	return &domain.PolicyViolation{
+		Code:    CodeAgeRestricted,
+		Message: fmt.Sprintf("genre %s requires age %d+", c.Book.Genre, minAge),
+	}
+}
+
+func age(birth, now time.Time) int {
+	years := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		years--
+	}
+	return years
+}
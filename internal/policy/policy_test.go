@@ -0,0 +1,131 @@
+package policy
+
+import (
+	"library/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// loans - n выдач книг одного автора и жанра
+func loans(n, authorID int, genre string) []domain.Book {
+	books := make([]domain.Book, n)
+	for i := range books {
+		books[i] = domain.Book{ID: 100 + i, AuthorID: authorID, Genre: genre}
+	}
+	return books
+}
+
+func TestRules(t *testing.T) {
+	now := date(2026, 6, 15)
+	adult := date(1990, 1, 1)
+	child := date(2016, 1, 1)
+	book := &domain.Book{ID: 1, AuthorID: 7, Genre: "Horror"}
+
+	tests := []struct {
+		name  string
+		rule  Rule
+		user  *domain.User
+		book  *domain.Book
+		loans []domain.Book
+		want  string
+	}{
+		{"blocked user", NewBlockedUsers([]int{2, 3}), &domain.User{ID: 3}, book, nil, CodeUserBlocked},
+		{"not blocked user", NewBlockedUsers([]int{2, 3}), &domain.User{ID: 4}, book, nil, ""},
+
+		{"loans under limit", MaxLoans{Limit: 3}, &domain.User{ID: 1}, book, loans(2, 1, ""), ""},
+		{"loans at limit", MaxLoans{Limit: 3}, &domain.User{ID: 1}, book, loans(3, 1, ""), CodeMaxLoansPerUser},
+		{"loans over limit", MaxLoans{Limit: 3}, &domain.User{ID: 1}, book, loans(4, 1, ""), CodeMaxLoansPerUser},
+
+		{"author under limit", MaxLoansPerAuthor{Limit: 2}, &domain.User{ID: 1}, book, append(loans(1, 7, ""), loans(5, 8, "")...), ""},
+		{"author at limit", MaxLoansPerAuthor{Limit: 2}, &domain.User{ID: 1}, book, loans(2, 7, ""), CodeMaxLoansPerAuthor},
+		{"author over limit", MaxLoansPerAuthor{Limit: 2}, &domain.User{ID: 1}, book, loans(3, 7, ""), CodeMaxLoansPerAuthor},
+
+		{"genre under limit", MaxLoansPerGenre{Limit: 2}, &domain.User{ID: 1}, book, append(loans(1, 1, "horror"), loans(5, 1, "Poetry")...), ""},
+		{"genre at limit, case-insensitive", MaxLoansPerGenre{Limit: 2}, &domain.User{ID: 1}, book, loans(2, 1, "HORROR"), CodeMaxLoansPerGenre},
+		{"genre over limit", MaxLoansPerGenre{Limit: 2}, &domain.User{ID: 1}, book, loans(3, 1, "Horror"), CodeMaxLoansPerGenre},
+		{"book without genre", MaxLoansPerGenre{Limit: 2}, &domain.User{ID: 1}, &domain.Book{AuthorID: 7}, loans(3, 1, ""), ""},
+
+		{"age unknown", NewAgeRestrictions([]AgeRestriction{{Genre: "horror", MinAge: 18}}), &domain.User{ID: 1}, book, nil, CodeAgeUnknown},
+		{"age too young", NewAgeRestrictions([]AgeRestriction{{Genre: "horror", MinAge: 18}}), &domain.User{ID: 1, BirthDate: &child}, book, nil, CodeAgeRestricted},
+		{"age old enough", NewAgeRestrictions([]AgeRestriction{{Genre: "horror", MinAge: 18}}), &domain.User{ID: 1, BirthDate: &adult}, book, nil, ""},
+		{"unrestricted genre, age unknown", NewAgeRestrictions([]AgeRestriction{{Genre: "horror", MinAge: 18}}), &domain.User{ID: 1}, &domain.Book{Genre: "Poetry"}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tt.rule.Evaluate(Checkout{User: tt.user, Book: tt.book, Loans: tt.loans, Now: now})
+			switch {
+			case tt.want == "" && v != nil:
+				t.Fatalf("Evaluate() = %+v, want no violation", v)
+			case tt.want != "" && (v == nil || v.Code != tt.want):
+				t.Fatalf("Evaluate() = %+v, want %s", v, tt.want)
+			}
+		})
+	}
+}
+
+func TestAge(t *testing.T) {
+	birth := date(2008, 6, 15)
+	tests := []struct {
+		name string
+		now  time.Time
+		want int
+	}{
+		{"day before birthday", date(2026, 6, 14), 17},
+		{"on birthday", date(2026, 6, 15), 18},
+		{"month before birthday", date(2026, 5, 20), 17},
+		{"after birthday", date(2026, 12, 31), 18},
+	}
+	for _, tt := range tests {
+		if got := age(birth, tt.now); got != tt.want {
+			t.Errorf("%s: age() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEngineFromConfig(t *testing.T) {
+	engine := NewEngineFromConfig(&Config{MaxLoansPerUser: 1, BlockedUsers: []int{5}})
+	violations := engine.Evaluate(Checkout{User: &domain.User{ID: 5}, Book: &domain.Book{}, Loans: loans(1, 1, ""), Now: time.Now()})
+	if len(violations) != 2 || violations[0].Code != CodeUserBlocked || violations[1].Code != CodeMaxLoansPerUser {
+		t.Fatalf("Evaluate() = %+v, want blocked and max loans", violations)
+	}
+
+	if v := NewEngineFromConfig(&Config{}).Evaluate(Checkout{User: &domain.User{ID: 5}, Book: &domain.Book{}, Loans: loans(50, 1, "")}); len(v) != 0 {
+		t.Fatalf("empty config: Evaluate() = %+v, want no violations", v)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	conf, err := LoadConfig(write("policy.json", `{"max_loans_per_user": 5, "age_restrictions": [{"genre": "horror", "min_age": 18}]}`))
+	if err != nil || conf.MaxLoansPerUser != 5 || len(conf.AgeRestrictions) != 1 {
+		t.Fatalf("LoadConfig() = %+v, %v", conf, err)
+	}
+
+	conf, err = LoadConfig(filepath.Join(dir, "missing.json"))
+	if err != nil || conf == nil || conf.MaxLoansPerUser != 0 {
+		t.Fatalf("missing file: LoadConfig() = %+v, %v, want empty config", conf, err)
+	}
+
+	for name, content := range map[string]string{
+		"truncated.json":  `{"max_loans_per_user": 5`,
+		"wrong_type.json": `{"max_loans_per_user": "five"}`,
+	} {
+		if conf, err := LoadConfig(write(name, content)); err == nil {
+			t.Errorf("%s: LoadConfig() = %+v, want error", name, conf)
+		}
+	}
+}
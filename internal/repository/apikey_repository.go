@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"library/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type APIKeyer interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	List(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.APIKey], error)
+	Revoke(ctx context.Context, id int) error
+	TouchLastUsed(ctx context.Context, id int, usedAt time.Time) error
+}
+
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) APIKeyer {
+	return &APIKeyRepository{db: db}
+}
+
+// lastUsedResolution - не чаще одной записи last_used_at в минуту на ключ
+const lastUsedResolution = time.Minute
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, created_by, created_at, last_used_at, revoked_at`
+
+// apiKeyRow - строка api_keys; массив областей сканируется через pq.StringArray
+type apiKeyRow struct {
+	ID         int            `db:"id"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"prefix"`
+	KeyHash    string         `db:"key_hash"`
+	Scopes     pq.StringArray `db:"scopes"`
+	CreatedBy  *int           `db:"created_by"`
+	CreatedAt  time.Time      `db:"created_at"`
+	LastUsedAt *time.Time     `db:"last_used_at"`
+	RevokedAt  *time.Time     `db:"revoked_at"`
+}
+
+func (row apiKeyRow) toDomain() domain.APIKey {
+	return domain.APIKey{
+		ID:         row.ID,
+		Name:       row.Name,
+		Prefix:     row.Prefix,
+		KeyHash:    row.KeyHash,
+		Scopes:     row.Scopes,
+		CreatedBy:  row.CreatedBy,
+		CreatedAt:  row.CreatedAt,
+		LastUsedAt: row.LastUsedAt,
+		RevokedAt:  row.RevokedAt,
+	}
+}
+
+func (r APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query,
+		key.Name, key.Prefix, key.KeyHash, pq.StringArray(key.Scopes), key.CreatedBy).
+		Scan(&key.ID, &key.CreatedAt)
+	return dbError(err, nil)
+}
+
+func (r APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	var row apiKeyRow
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &row, query, prefix)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	key := row.toDomain()
+	return &key, nil
+}
+
+func (r APIKeyRepository) List(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.APIKey], error) {
+	keys := listQuery[apiKeyRow]{
+		base:     `SELECT ` + apiKeyColumns + ` FROM api_keys`,
+		idColumn: "id",
+		id:       func(row apiKeyRow) int { return row.ID },
+		sorts: map[string]sortField[apiKeyRow]{
+			"id":         {listField{"id", kindInt}, func(row apiKeyRow) interface{} { return row.ID }},
+			"name":       {listField{"name", kindString}, func(row apiKeyRow) interface{} { return row.Name }},
+			"created_at": {listField{"created_at", kindTime}, func(row apiKeyRow) interface{} { return row.CreatedAt }},
+		},
+		defaultSort: "id",
+		filters: map[string]listField{
+			"name":       {"name", kindString},
+			"created_by": {"created_by", kindInt},
+			"created_at": {"created_at", kindTime},
+		},
+	}
+	rows, err := listPage(ctx, conn(ctx, r.db), keys, req)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.Page[domain.APIKey]{
+		Items:         make([]domain.APIKey, 0, len(rows.Items)),
+		NextCursor:    rows.NextCursor,
+		TotalEstimate: rows.TotalEstimate,
+	}
+	for _, row := range rows.Items {
+		page.Items = append(page.Items, row.toDomain())
+	}
+	return page, nil
+}
+
+func (r APIKeyRepository) Revoke(ctx context.Context, id int) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP)
+		WHERE id = $1
+		RETURNING id
+	`
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, id).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &domain.ErrAPIKeyNotFound{KeyID: id}
+	}
+	return dbError(err, nil)
+}
+
+func (r APIKeyRepository) TouchLastUsed(ctx context.Context, id int, usedAt time.Time) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, usedAt, usedAt.Add(-lastUsedResolution))
+	return dbError(err, nil)
+}
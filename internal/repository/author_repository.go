@@ -6,13 +6,18 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Authorer interface {
 	Create(ctx context.Context, author *domain.Author) error
 	GetByID(ctx context.Context, id int) (*domain.Author, error)
+	GetByName(ctx context.Context, name string) (*domain.Author, error)
+	Exists(ctx context.Context, id int) (bool, error)
+	Update(ctx context.Context, author *domain.Author) error
 	DeleteAuthor(ctx context.Context, id int) error
-	GetAll(ctx context.Context) ([]*domain.Author, error)
+	List(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.Author], error)
+	ListBooks(ctx context.Context, authorID int, req domain.PageRequest) (*domain.Page[domain.Book], error)
 	GetTopAuthors(ctx context.Context, limit int) ([]*domain.AuthorWithRentCount, error)
 	GetByBooksAuthor(ctx context.Context, idAuthor int) ([]domain.Book, error)
 }
@@ -27,47 +32,48 @@ func NewAuthorRepository(db *sqlx.DB) *AuthorRepository {
 
 func (r *AuthorRepository) Create(ctx context.Context, author *domain.Author) error {
 	query := `INSERT INTO authors (name, biography, created_at) VALUES ($1, $2, $3) RETURNING id`
-	err := r.db.QueryRowContext(ctx, query, author.Name, author.Biography, time.Now()).Scan(&author.ID)
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, author.Name, author.Biography, time.Now()).Scan(&author.ID)
 
 	if len(author.Books) > 0 {
 		bookQuery := `
-			INSERT INTO books (title, author_id, available, created_at)
-			VALUES ($1, $2, $3, $4, $5)
+			INSERT INTO books (title, author_id, available, genre, created_at)
+			VALUES ($1, $2, FALSE, $3, $4)
 			RETURNING id
 		`
 
 		for i := range author.Books {
 			book := &author.Books[i]
 			book.AuthorID = author.ID
+			book.Available = false
 			book.CreatedAt = time.Now()
 
-			err = r.db.QueryRowContext(
+			err = conn(ctx, r.db).QueryRowxContext(
 				ctx,
 				bookQuery,
 				book.Title,
 				book.AuthorID,
-				book.Available,
+				book.Genre,
 				book.CreatedAt,
 			).Scan(&book.ID)
 			if err != nil {
-				return err
+				return dbError(err, nil)
 			}
 		}
 	}
-	return err
+	return dbError(err, nil)
 }
 
 func (r *AuthorRepository) GetByID(ctx context.Context, id int) (*domain.Author, error) {
-	query := `SELECT id, name, biography, created_at FROM authors WHERE id = $1`
+	query := `SELECT id, name, COALESCE(biography, '') AS biography, created_at FROM authors WHERE id = $1`
 	var author domain.Author
 
-	err := r.db.GetContext(ctx, &author, query, id)
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &author, query, id)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, &domain.ErrAuthorNotFound{AuthorID: id})
 	}
 	books, err := r.GetByBooksAuthor(ctx, author.ID)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, nil)
 	}
 
 	if books != nil {
@@ -77,37 +83,92 @@ func (r *AuthorRepository) GetByID(ctx context.Context, id int) (*domain.Author,
 	return &author, err
 }
 
-func (r *AuthorRepository) GetAll(ctx context.Context) ([]*domain.Author, error) {
-	query := `SELECT id, name, biography, created_at FROM authors`
-	rows, err := r.db.QueryContext(ctx, query)
+// GetByName ищет автора по имени без учёта регистра, без его книг; из однофамильцев берётся самый ранний
+func (r *AuthorRepository) GetByName(ctx context.Context, name string) (*domain.Author, error) {
+	query := `
+		SELECT id, name, COALESCE(biography, '') AS biography, created_at
+		FROM authors
+		WHERE lower(name) = lower($1)
+		ORDER BY id
+		LIMIT 1
+	`
+	var author domain.Author
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &author, query, name)
+	if err != nil {
+		return nil, dbError(err, &domain.ErrAuthorNotFound{Name: name})
+	}
+	return &author, nil
+}
+
+func (r *AuthorRepository) Exists(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM authors WHERE id = $1)`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &exists, query, id)
+	if err != nil {
+		return false, dbError(err, nil)
+	}
+	return exists, nil
+}
+
+func (r *AuthorRepository) Update(ctx context.Context, author *domain.Author) error {
+	query := `UPDATE authors SET name = $1, biography = $2 WHERE id = $3`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, author.Name, author.Biography, author.ID)
+	if err != nil {
+		return dbError(err, nil)
+	}
+	return affected(result, &domain.ErrAuthorNotFound{AuthorID: author.ID})
+}
+
+// List - страница авторов вместе с их книгами
+func (r *AuthorRepository) List(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.Author], error) {
+	page, err := listPage(ctx, conn(ctx, r.db), authorList(), req)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var authors []*domain.Author
-	for rows.Next() {
-		author := &domain.Author{}
-		if err := rows.Scan(&author.ID, &author.Name, &author.Biography, &author.CreatedAt); err != nil {
-			return nil, err
-		}
-		books, err := r.GetByBooksAuthor(ctx, author.ID)
-		if err != nil {
-			return nil, err
-		}
+	ids := make([]int, 0, len(page.Items))
+	for _, author := range page.Items {
+		ids = append(ids, author.ID)
+	}
+	books, err := r.booksByAuthors(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Items {
+		page.Items[i].Books = books[page.Items[i].ID]
+	}
+	return page, nil
+}
 
-		if books != nil {
-			author.Books = append(author.Books, books...)
-		}
+// ListBooks - страница книг автора, с теми же сортировками и фильтрами, что у каталога
+func (r *AuthorRepository) ListBooks(ctx context.Context, authorID int, req domain.PageRequest) (*domain.Page[domain.Book], error) {
+	books := bookList()
+	books.where = []string{"b.author_id = $1"}
+	books.args = []interface{}{authorID}
+	return listPage(ctx, conn(ctx, r.db), books, req)
+}
 
-		authors = append(authors, author)
+func authorList() listQuery[domain.Author] {
+	return listQuery[domain.Author]{
+		base:     `SELECT a.id, a.name, COALESCE(a.biography, '') AS biography, a.created_at FROM authors a`,
+		idColumn: "a.id",
+		id:       func(a domain.Author) int { return a.ID },
+		sorts: map[string]sortField[domain.Author]{
+			"id":         {listField{"a.id", kindInt}, func(a domain.Author) interface{} { return a.ID }},
+			"name":       {listField{"a.name", kindString}, func(a domain.Author) interface{} { return a.Name }},
+			"created_at": {listField{"a.created_at", kindTime}, func(a domain.Author) interface{} { return a.CreatedAt }},
+		},
+		defaultSort: "id",
+		filters: map[string]listField{
+			"name":       {"a.name", kindString},
+			"created_at": {"a.created_at", kindTime},
+		},
 	}
-	return authors, nil
 }
 
 func (r *AuthorRepository) GetTopAuthors(ctx context.Context, limit int) ([]*domain.AuthorWithRentCount, error) {
 	query := `
-		SELECT a.id, a.name, a.biography, a.created_at, COUNT(r.id) as rental_count
+		SELECT a.id, a.name, COALESCE(a.biography, ''), a.created_at, COUNT(r.id) as rental_count
 		FROM authors a
 		LEFT JOIN books b ON b.author_id = a.id
 		LEFT JOIN book_rental r ON r.book_id = b.id
@@ -115,13 +176,16 @@ func (r *AuthorRepository) GetTopAuthors(ctx context.Context, limit int) ([]*dom
 		ORDER BY rental_count DESC
 		LIMIT $1
 	`
-	rows, err := r.db.QueryContext(ctx, query, limit)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, nil)
 	}
 	defer rows.Close()
 
-	var result []*domain.AuthorWithRentCount
+	var (
+		result []*domain.AuthorWithRentCount
+		ids    []int
+	)
 	for rows.Next() {
 		item := &domain.AuthorWithRentCount{}
 		err := rows.Scan(
@@ -132,41 +196,72 @@ func (r *AuthorRepository) GetTopAuthors(ctx context.Context, limit int) ([]*dom
 			&item.RentCount,
 		)
 		if err != nil {
-			return nil, err
-		}
-		books, err := r.GetByBooksAuthor(ctx, item.Author.ID)
-		if err != nil {
-			return nil, err
-		}
-
-		if books != nil {
-			item.Author.Books = append(item.Author.Books, books...)
+			return nil, dbError(err, nil)
 		}
 		result = append(result, item)
+		ids = append(ids, item.Author.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, dbError(err, nil)
+	}
+
+	books, err := r.booksByAuthors(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range result {
+		item.Author.Books = books[item.Author.ID]
 	}
 	return result, nil
 }
 
 func (r *AuthorRepository) DeleteAuthor(ctx context.Context, id int) error {
 	query := `DELETE FROM authors WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
-		return err
+		return dbError(err, nil)
 	}
-	return nil
+	return affected(result, &domain.ErrAuthorNotFound{AuthorID: id})
+}
+
+// booksByAuthors - книги нескольких авторов одним запросом, сгруппированные по автору
+func (r *AuthorRepository) booksByAuthors(ctx context.Context, authorIDs []int) (map[int][]domain.Book, error) {
+	byAuthor := make(map[int][]domain.Book, len(authorIDs))
+	if len(authorIDs) == 0 {
+		return byAuthor, nil
+	}
+
+	var books []domain.Book
+	query := `
+		SELECT b.id, b.title, b.author_id, b.available, b.genre, b.loan_period_days,
+			b.isbn, b.publication_year, b.created_at
+		FROM books b
+		WHERE b.author_id = ANY($1)
+		ORDER BY b.id
+	`
+	err := sqlx.SelectContext(ctx, conn(ctx, r.db), &books, query, pq.Array(authorIDs))
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+
+	for _, book := range books {
+		byAuthor[book.AuthorID] = append(byAuthor[book.AuthorID], book)
+	}
+	return byAuthor, nil
 }
 
 func (r *AuthorRepository) GetByBooksAuthor(ctx context.Context, idAuthor int) ([]domain.Book, error) {
 	var books []domain.Book
 
 	query := `
-		SELECT b.id, b.title, b.author_id, b.available, b.created_at
+		SELECT b.id, b.title, b.author_id, b.available, b.genre, b.loan_period_days,
+			b.isbn, b.publication_year, b.created_at
 		FROM books b
 		WHERE b.author_id = $1
 	`
-	err := r.db.SelectContext(ctx, &books, query, idAuthor)
+	err := sqlx.SelectContext(ctx, conn(ctx, r.db), &books, query, idAuthor)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, nil)
 	}
 
 	return books, nil
@@ -2,86 +2,171 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"library/internal/domain"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Booker interface {
 	Create(ctx context.Context, book *domain.Book) error
 	GetByID(ctx context.Context, id int) (*domain.Book, error)
+	GetByISBN(ctx context.Context, isbn string) (*domain.Book, error)
+	List(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.Book], error)
+	GetByIDForUpdate(ctx context.Context, id int) (*domain.Book, error)
 	Update(ctx context.Context, book *domain.Book) error
 	Delete(ctx context.Context, id int) error
 }
 
 type BookRepository struct {
-	db         *sqlx.DB
-	authorRepo Authorer
+	db *sqlx.DB
 }
 
-func NewBookRepository(db *sqlx.DB, author Authorer) *BookRepository {
-	return &BookRepository{db: db, authorRepo: author}
+func NewBookRepository(db *sqlx.DB) *BookRepository {
+	return &BookRepository{db: db}
 }
+
+// queryBookWithAuthor - книга вместе с автором одним запросом
+const queryBookWithAuthor = `
+	SELECT b.id, b.title, b.author_id, b.available, b.genre, b.loan_period_days,
+		b.isbn, b.publication_year, b.created_at,
+		a.id AS "author.id", a.name AS "author.name",
+		COALESCE(a.biography, '') AS "author.biography", a.created_at AS "author.created_at"
+	FROM books b
+	JOIN authors a ON a.id = b.author_id
+`
+
+// Create добавляет запись каталога без экземпляров, поэтому книга создаётся недоступной
 func (r *BookRepository) Create(ctx context.Context, book *domain.Book) error {
 	query := `
-		INSERT INTO books (title, author_id, available, created_at)
-		VALUES (:title, :author_id, :available, :created_at)
+		INSERT INTO books (title, author_id, available, genre, loan_period_days, isbn, publication_year, created_at)
+		VALUES ($1, $2, FALSE, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, book)
-
+	err := conn(ctx, r.db).QueryRowxContext(
+		ctx,
+		query,
+		book.Title,
+		book.AuthorID,
+		book.Genre,
+		book.LoanPeriodDays,
+		book.ISBN,
+		book.PublicationYear,
+		book.CreatedAt,
+	).Scan(&book.ID)
 	if err != nil {
-		return err
+		return bookConflict(err, book)
 	}
 
+	book.Available = false
 	return nil
 }
 
 func (r *BookRepository) GetByID(ctx context.Context, id int) (*domain.Book, error) {
+	var book domain.Book
+	query := queryBookWithAuthor + ` WHERE b.id = $1`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &book, query, id)
+	if err != nil {
+		return nil, dbError(err, &domain.ErrBookNotFound{BookID: id})
+	}
+
+	return &book, nil
+}
+
+// GetByISBN ищет книгу по нормализованному ISBN-13
+func (r *BookRepository) GetByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	var book domain.Book
+	query := queryBookWithAuthor + ` WHERE b.isbn = $1`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &book, query, isbn)
+	if err != nil {
+		return nil, dbError(err, &domain.ErrBookNotFound{ISBN: isbn})
+	}
+
+	return &book, nil
+}
+
+// List - страница каталога с авторами
+func (r *BookRepository) List(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.Book], error) {
+	return listPage(ctx, conn(ctx, r.db), bookList(), req)
+}
+
+// bookList - сортировки и фильтры каталога
+func bookList() listQuery[domain.Book] {
+	return listQuery[domain.Book]{
+		base:     queryBookWithAuthor,
+		idColumn: "b.id",
+		id:       func(b domain.Book) int { return b.ID },
+		sorts: map[string]sortField[domain.Book]{
+			"id":         {listField{"b.id", kindInt}, func(b domain.Book) interface{} { return b.ID }},
+			"title":      {listField{"b.title", kindString}, func(b domain.Book) interface{} { return b.Title }},
+			"created_at": {listField{"b.created_at", kindTime}, func(b domain.Book) interface{} { return b.CreatedAt }},
+		},
+		defaultSort: "id",
+		filters: map[string]listField{
+			"author_id":  {"b.author_id", kindInt},
+			"available":  {"b.available", kindBool},
+			"title":      {"b.title", kindString},
+			"genre":      {"b.genre", kindString},
+			"created_at": {"b.created_at", kindTime},
+		},
+	}
+}
+
+// GetByIDForUpdate блокирует строку книги до конца транзакции
+func (r *BookRepository) GetByIDForUpdate(ctx context.Context, id int) (*domain.Book, error) {
 	var book domain.Book
 	query := `
-		SELECT *
+		SELECT b.id, b.title, b.author_id, b.available, b.genre, b.loan_period_days,
+			b.isbn, b.publication_year, b.created_at
 		FROM books b
 		WHERE b.id = $1
+		FOR UPDATE
 	`
-	err := r.db.GetContext(ctx, &book, query, id)
-	if err != nil {
-		return nil, err
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &book, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &domain.ErrBookNotFound{BookID: id}
 	}
-	author, err := r.authorRepo.GetByID(ctx, book.AuthorID)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, nil)
 	}
-	book.Author = author
 
 	return &book, nil
 }
 
+// Update сохраняет описание книги; доступность следует за экземплярами и здесь не меняется
 func (r *BookRepository) Update(ctx context.Context, book *domain.Book) error {
 	query := `
         UPDATE books 
         SET title = $1, 
             author_id = $2, 
-            available = $3 
-        WHERE id = $4
+            genre = $3,
+            loan_period_days = $4,
+            isbn = $5,
+            publication_year = $6
+        WHERE id = $7
     `
 
-	result, err := r.db.ExecContext(
+	result, err := conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		book.Title,
 		book.AuthorID,
-		book.Available,
+		book.Genre,
+		book.LoanPeriodDays,
+		book.ISBN,
+		book.PublicationYear,
 		book.ID,
 	)
 	if err != nil {
-		return err
+		return bookConflict(err, book)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return dbError(err, nil)
 	}
 	if rowsAffected == 0 {
 		return &domain.ErrBookNotFound{BookID: book.ID}
@@ -92,9 +177,18 @@ func (r *BookRepository) Update(ctx context.Context, book *domain.Book) error {
 
 func (r *BookRepository) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM books WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
-		return err
+		return dbError(err, nil)
 	}
-	return nil
+	return affected(result, &domain.ErrBookNotFound{BookID: id})
+}
+
+// bookConflict превращает нарушение уникального индекса ISBN в ошибку повторяющегося ISBN
+func bookConflict(err error, book *domain.Book) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation && pqErr.Constraint == "ux_books_isbn" && book.ISBN != nil {
+		return &domain.ErrDuplicateISBN{ISBN: *book.ISBN}
+	}
+	return dbError(err, nil)
 }
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"library/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Copier interface {
+	Create(ctx context.Context, c *domain.Copy) error
+	GetByID(ctx context.Context, id int) (*domain.Copy, error)
+	GetByBarcode(ctx context.Context, barcode string) (*domain.Copy, error)
+	ListByBook(ctx context.Context, bookID int) ([]domain.Copy, error)
+	FirstAvailable(ctx context.Context, bookID int) (*domain.Copy, error)
+	SetAvailable(ctx context.Context, id int, available bool) error
+	Retire(ctx context.Context, id int, at time.Time) error
+}
+
+// CopyRepository - экземпляры книг. Экземпляры меняются под блокировкой строки их книги,
+// поэтому чтение экземпляра строку не блокирует
+type CopyRepository struct {
+	db *sqlx.DB
+}
+
+func NewCopyRepository(db *sqlx.DB) Copier {
+	return &CopyRepository{db: db}
+}
+
+const copyColumns = `id, book_id, barcode, available, retired_at, created_at`
+
+func (r CopyRepository) Create(ctx context.Context, c *domain.Copy) error {
+	query := `
+		INSERT INTO book_copies (book_id, barcode, available)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, c.BookID, c.Barcode, c.Available).
+		Scan(&c.ID, &c.CreatedAt)
+	return dbError(err, nil)
+}
+
+func (r CopyRepository) GetByID(ctx context.Context, id int) (*domain.Copy, error) {
+	var c domain.Copy
+	query := `SELECT ` + copyColumns + ` FROM book_copies WHERE id = $1`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &c, query, id)
+	if err != nil {
+		return nil, dbError(err, &domain.ErrCopyNotFound{CopyID: id})
+	}
+	return &c, nil
+}
+
+func (r CopyRepository) GetByBarcode(ctx context.Context, barcode string) (*domain.Copy, error) {
+	var c domain.Copy
+	query := `SELECT ` + copyColumns + ` FROM book_copies WHERE barcode = $1`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &c, query, barcode)
+	if err != nil {
+		return nil, dbError(err, &domain.ErrCopyNotFound{Barcode: barcode})
+	}
+	return &c, nil
+}
+
+func (r CopyRepository) ListByBook(ctx context.Context, bookID int) ([]domain.Copy, error) {
+	copies := []domain.Copy{}
+	query := `SELECT ` + copyColumns + ` FROM book_copies WHERE book_id = $1 ORDER BY id`
+	err := sqlx.SelectContext(ctx, conn(ctx, r.db), &copies, query, bookID)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	return copies, nil
+}
+
+// FirstAvailable - свободный экземпляр книги, nil если все выданы, отложены или списаны.
+// Экземпляры, заблокированные другими выдачами, пропускаются, чтобы одновременные выдачи не ждали друг друга.
+func (r CopyRepository) FirstAvailable(ctx context.Context, bookID int) (*domain.Copy, error) {
+	var c domain.Copy
+	query := `
+		SELECT ` + copyColumns + `
+		FROM book_copies
+		WHERE book_id = $1 AND available
+		ORDER BY id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &c, query, bookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	return &c, nil
+}
+
+// SetAvailable меняет доступность экземпляра; books.available пересчитывает триггер из миграции 000013
+func (r CopyRepository) SetAvailable(ctx context.Context, id int, available bool) error {
+	query := `UPDATE book_copies SET available = $1 WHERE id = $2 AND retired_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, available, id)
+	if err != nil {
+		return dbError(err, nil)
+	}
+	return affected(result, &domain.ErrCopyNotFound{CopyID: id})
+}
+
+func (r CopyRepository) Retire(ctx context.Context, id int, at time.Time) error {
+	query := `UPDATE book_copies SET available = FALSE, retired_at = $1 WHERE id = $2 AND retired_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, at, id)
+	if err != nil {
+		return dbError(err, nil)
+	}
+	return affected(result, &domain.ErrCopyNotFound{CopyID: id})
+}
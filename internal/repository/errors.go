@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"library/internal/domain"
+	"net"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+	pqNotNullViolation    = "23502"
+	pqCheckViolation      = "23514"
+
+	pqClassDataException      = "22"
+	pqClassConnection         = "08"
+	pqClassInsufficientSource = "53"
+	pqClassOperatorIntervened = "57"
+)
+
+// dbError переводит ошибки драйвера в доменные ошибки; notFound подставляется вместо sql.ErrNoRows.
+// Исходная ошибка сохраняется через Unwrap, чтобы TxManager видел ошибки сериализации.
+func dbError(err error, notFound error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) && notFound != nil {
+		return notFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == pqUniqueViolation:
+			return &domain.ErrDuplicate{Constraint: pqErr.Constraint, Err: err}
+		case pqErr.Code == pqForeignKeyViolation && strings.Contains(pqErr.Detail, "still referenced"):
+			return &domain.ErrStillReferenced{Constraint: pqErr.Constraint, Err: err}
+		case pqErr.Code == pqForeignKeyViolation:
+			return &domain.ErrInvalidReference{Constraint: pqErr.Constraint, Err: err}
+		case pqErr.Code == pqNotNullViolation:
+			return &domain.ErrInvalidData{Reason: pqErr.Column + " is required", Err: err}
+		case pqErr.Code == pqCheckViolation:
+			return &domain.ErrInvalidData{Reason: "violates " + pqErr.Constraint, Err: err}
+		case pqErr.Code.Class() == pqClassDataException:
+			return &domain.ErrInvalidData{Reason: pqErr.Message, Err: err}
+		case pqErr.Code.Class() == pqClassConnection,
+			pqErr.Code.Class() == pqClassInsufficientSource,
+			pqErr.Code.Class() == pqClassOperatorIntervened:
+			return &domain.ErrStorageUnavailable{Err: err}
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr) {
+		return &domain.ErrStorageUnavailable{Err: err}
+	}
+	return err
+}
+
+// affected возвращает notFound, если запрос не затронул ни одной строки
+func affected(result sql.Result, notFound error) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return dbError(err, nil)
+	}
+	if rows == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"library/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Exporter - построчное чтение таблиц для выгрузок. Строки читаются курсором и передаются в fn по одной,
+// поэтому выгрузка любого размера не держит таблицу в памяти
+type Exporter interface {
+	StreamBooks(ctx context.Context, fn func(domain.Book) error) error
+	StreamAuthors(ctx context.Context, fn func(domain.Author) error) error
+	StreamUsers(ctx context.Context, fn func(domain.User) error) error
+	StreamRentals(ctx context.Context, fn func(domain.BookRental) error) error
+}
+
+type ExportRepository struct {
+	db *sqlx.DB
+}
+
+func NewExportRepository(db *sqlx.DB) Exporter {
+	return &ExportRepository{db: db}
+}
+
+func (r *ExportRepository) StreamBooks(ctx context.Context, fn func(domain.Book) error) error {
+	return streamRows(ctx, conn(ctx, r.db), queryBookWithAuthor+` ORDER BY b.id`, fn)
+}
+
+func (r *ExportRepository) StreamAuthors(ctx context.Context, fn func(domain.Author) error) error {
+	query := `SELECT id, name, COALESCE(biography, '') AS biography, created_at FROM authors ORDER BY id`
+	return streamRows(ctx, conn(ctx, r.db), query, fn)
+}
+
+// StreamUsers - читатели с балансом; баланс считается одним проходом по журналу, а не подзапросом на строку
+func (r *ExportRepository) StreamUsers(ctx context.Context, fn func(domain.User) error) error {
+	query := `
+		SELECT u.id, u.name, u.email, u.role, u.birth_date, u.created_at, COALESCE(l.balance, 0) AS balance
+		FROM users u
+		LEFT JOIN (
+			SELECT user_id, SUM(CASE WHEN kind = 'fine' THEN amount ELSE -amount END) AS balance
+			FROM user_ledger
+			GROUP BY user_id
+		) l ON l.user_id = u.id
+		ORDER BY u.id
+	`
+	return streamRows(ctx, conn(ctx, r.db), query, fn)
+}
+
+func (r *ExportRepository) StreamRentals(ctx context.Context, fn func(domain.BookRental) error) error {
+	query := `
+		SELECT id, book_id, copy_id, user_id, rental_date, due_date, return_date, renewals, created_at,
+			(return_date IS NULL AND due_date < now()) AS overdue
+		FROM book_rental
+		ORDER BY id
+	`
+	return streamRows(ctx, conn(ctx, r.db), query, fn)
+}
+
+// streamRows сканирует строки по одной; lib/pq читает результат из сокета по мере продвижения курсора
+func streamRows[T any](ctx context.Context, q sqlx.QueryerContext, query string, fn func(T) error) error {
+	rows, err := q.QueryxContext(ctx, query)
+	if err != nil {
+		return dbError(err, nil)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v T
+		if err := rows.StructScan(&v); err != nil {
+			return dbError(err, nil)
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return dbError(rows.Err(), nil)
+}
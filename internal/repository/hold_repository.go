@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"library/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Holder interface {
+	Create(ctx context.Context, hold *domain.Hold) error
+	GetByID(ctx context.Context, id int) (*domain.Hold, error)
+	ListActiveByBook(ctx context.Context, bookID int) ([]domain.Hold, error)
+	ListActiveByUser(ctx context.Context, userID int) ([]domain.Hold, error)
+	NextWaiting(ctx context.Context, bookID int) (*domain.Hold, error)
+	HasWaiting(ctx context.Context, bookID int) (bool, error)
+	GetReadyForUser(ctx context.Context, bookID, userID int) (*domain.Hold, error)
+	GetReadyByCopy(ctx context.Context, copyID int) (*domain.Hold, error)
+	ListExpired(ctx context.Context, now time.Time) ([]domain.Hold, error)
+	Update(ctx context.Context, hold *domain.Hold) error
+}
+
+type HoldRepository struct {
+	db *sqlx.DB
+}
+
+func NewHoldRepository(db *sqlx.DB) Holder {
+	return &HoldRepository{db: db}
+}
+
+const holdColumns = `id, book_id, copy_id, user_id, status, ready_at, expires_at, created_at`
+
+func (r HoldRepository) Create(ctx context.Context, hold *domain.Hold) error {
+	query := `
+		INSERT INTO holds (book_id, user_id, status)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err := conn(ctx, r.db).QueryRowxContext(ctx, query, hold.BookID, hold.UserID, hold.Status).
+		Scan(&hold.ID, &hold.CreatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+		return &domain.ErrHoldNotAllowed{BookID: hold.BookID, Reason: "user already holds this book"}
+	}
+	return dbError(err, nil)
+}
+
+func (r HoldRepository) GetByID(ctx context.Context, id int) (*domain.Hold, error) {
+	var hold domain.Hold
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE id = $1 FOR UPDATE`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &hold, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &domain.ErrHoldNotFound{HoldID: id}
+	}
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	return &hold, nil
+}
+
+func (r HoldRepository) ListActiveByBook(ctx context.Context, bookID int) ([]domain.Hold, error) {
+	var holds []domain.Hold
+	query := `
+		SELECT ` + holdColumns + `
+		FROM holds
+		WHERE book_id = $1 AND status IN ('waiting', 'ready')
+		ORDER BY status = 'waiting', created_at, id
+	`
+	err := sqlx.SelectContext(ctx, conn(ctx, r.db), &holds, query, bookID)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	return holds, nil
+}
+
+func (r HoldRepository) ListActiveByUser(ctx context.Context, userID int) ([]domain.Hold, error) {
+	var holds []domain.Hold
+	query := `
+		SELECT ` + holdColumns + `
+		FROM holds
+		WHERE user_id = $1 AND status IN ('waiting', 'ready')
+		ORDER BY created_at, id
+	`
+	err := sqlx.SelectContext(ctx, conn(ctx, r.db), &holds, query, userID)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	return holds, nil
+}
+
+// NextWaiting - первая бронь в очереди на книгу, nil если очередь пуста
+func (r HoldRepository) NextWaiting(ctx context.Context, bookID int) (*domain.Hold, error) {
+	return r.getOne(ctx, `
+		SELECT `+holdColumns+`
+		FROM holds
+		WHERE book_id = $1 AND status = 'waiting'
+		ORDER BY created_at, id
+		LIMIT 1
+		FOR UPDATE
+	`, bookID)
+}
+
+func (r HoldRepository) HasWaiting(ctx context.Context, bookID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM holds WHERE book_id = $1 AND status = 'waiting')`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &exists, query, bookID)
+	if err != nil {
+		return false, dbError(err, nil)
+	}
+	return exists, nil
+}
+
+// GetReadyForUser - готовая бронь читателя, экземпляр для которой лежит на полке выдачи, nil если такой нет
+func (r HoldRepository) GetReadyForUser(ctx context.Context, bookID, userID int) (*domain.Hold, error) {
+	return r.getOne(ctx, `
+		SELECT `+holdColumns+`
+		FROM holds
+		WHERE book_id = $1 AND user_id = $2 AND status = 'ready'
+		FOR UPDATE
+	`, bookID, userID)
+}
+
+// GetReadyByCopy - готовая бронь, для которой отложен экземпляр, nil если экземпляр не отложен
+func (r HoldRepository) GetReadyByCopy(ctx context.Context, copyID int) (*domain.Hold, error) {
+	return r.getOne(ctx, `
+		SELECT `+holdColumns+`
+		FROM holds
+		WHERE copy_id = $1 AND status = 'ready'
+		FOR UPDATE
+	`, copyID)
+}
+
+func (r HoldRepository) ListExpired(ctx context.Context, now time.Time) ([]domain.Hold, error) {
+	var holds []domain.Hold
+	query := `
+		SELECT ` + holdColumns + `
+		FROM holds
+		WHERE status = 'ready' AND expires_at < $1
+		ORDER BY expires_at
+	`
+	err := sqlx.SelectContext(ctx, conn(ctx, r.db), &holds, query, now)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	return holds, nil
+}
+
+func (r HoldRepository) Update(ctx context.Context, hold *domain.Hold) error {
+	query := `UPDATE holds SET status = $1, copy_id = $2, ready_at = $3, expires_at = $4 WHERE id = $5`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, hold.Status, hold.CopyID, hold.ReadyAt, hold.ExpiresAt, hold.ID)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return dbError(err, nil)
+	}
+	if rowsAffected == 0 {
+		return &domain.ErrHoldNotFound{HoldID: hold.ID}
+	}
+	return nil
+}
+
+func (r HoldRepository) getOne(ctx context.Context, query string, args ...interface{}) (*domain.Hold, error) {
+	var hold domain.Hold
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &hold, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	return &hold, nil
+}
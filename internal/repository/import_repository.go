@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Importer interface {
+	ImportBooks(ctx context.Context, rows []domain.ImportRow) (*domain.ImportReport, error)
+}
+
+// importedLine - строка файла импорта и книга каталога, созданная по ней или занявшая её ISBN,
+// либо уже занятый штрихкод, который строка получила бы для своего экземпляра
+type importedLine struct {
+	Line    int    `db:"line"`
+	BookID  int    `db:"book_id"`
+	Barcode string `db:"barcode"`
+}
+
+type ImportRepository struct {
+	db *sqlx.DB
+}
+
+func NewImportRepository(db *sqlx.DB) Importer {
+	return &ImportRepository{db: db}
+}
+
+// ImportBooks загружает строки через COPY во временную таблицу и переносит их в каталог несколькими запросами.
+// Строка с ISBN, который уже есть в каталоге, или со штрихкодом экземпляра, который уже занят, отклоняется;
+// авторы оставшихся строк находятся по имени без учёта регистра или создаются.
+// Вызывается только внутри транзакции: временная таблица удаляется при её завершении.
+func (r ImportRepository) ImportBooks(ctx context.Context, rows []domain.ImportRow) (*domain.ImportReport, error) {
+	tx, ok := ctx.Value(txKey{}).(*sqlx.Tx)
+	if !ok {
+		return nil, errors.New("catalogue import must run in a transaction")
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE import_books (
+			line INTEGER PRIMARY KEY,
+			title TEXT NOT NULL,
+			author_name TEXT NOT NULL,
+			isbn TEXT,
+			copies INTEGER NOT NULL,
+			author_id INTEGER,
+			book_id INTEGER
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+
+	if err := copyImportRows(ctx, tx, rows); err != nil {
+		return nil, err
+	}
+
+	report := &domain.ImportReport{}
+	var conflicts []importedLine
+	err = sqlx.SelectContext(ctx, tx, &conflicts, `
+		DELETE FROM import_books s USING books b
+		WHERE b.isbn = s.isbn
+		RETURNING s.line, b.id AS book_id
+	`)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	for _, c := range conflicts {
+		report.Lines = append(report.Lines, domain.ImportLine{
+			Line:   c.Line,
+			Status: domain.ImportRejected,
+			Errors: []domain.FieldError{{Field: "isbn", Message: fmt.Sprintf("already belongs to book with ID %d", c.BookID)}},
+		})
+	}
+
+	// идентификаторы книг выдаются заранее, чтобы сопоставить созданные книги со строками файла
+	// и построить штрихкоды экземпляров до вставки
+	queries := []string{
+		`UPDATE import_books SET book_id = nextval(pg_get_serial_sequence('books', 'id'))`,
+		`CREATE TEMP TABLE import_copies ON COMMIT DROP AS
+		SELECT s.line, s.book_id, 'B' || lpad(s.book_id::text, 9, '0') || CASE WHEN n > 1 THEN '-' || n ELSE '' END AS barcode
+		FROM import_books s, generate_series(1, s.copies) n`,
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return nil, dbError(err, nil)
+		}
+	}
+
+	// штрихкод такого вида может уже занять экземпляр, добавленный до резервирования формы B000000000;
+	// такая строка отклоняется, иначе нарушение уникальности откатило бы весь импорт
+	var taken []importedLine
+	err = sqlx.SelectContext(ctx, tx, &taken, `
+		DELETE FROM import_books s USING (
+			SELECT DISTINCT ON (ic.line) ic.line, ic.barcode
+			FROM import_copies ic
+			JOIN book_copies c ON c.barcode = ic.barcode
+			ORDER BY ic.line, ic.barcode
+		) t
+		WHERE s.line = t.line
+		RETURNING s.line, t.barcode
+	`)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	for _, c := range taken {
+		report.Lines = append(report.Lines, domain.ImportLine{
+			Line:   c.Line,
+			Status: domain.ImportRejected,
+			Errors: []domain.FieldError{{Field: "copies", Message: fmt.Sprintf("barcode %s is already taken by another copy", c.Barcode)}},
+		})
+	}
+
+	// авторы создаются только для строк, которые прошли проверки ISBN и штрихкодов
+	err = tx.QueryRowxContext(ctx, `
+		WITH created AS (
+			INSERT INTO authors (name)
+			SELECT DISTINCT ON (lower(s.author_name)) s.author_name
+			FROM import_books s
+			WHERE NOT EXISTS (SELECT 1 FROM authors a WHERE lower(a.name) = lower(s.author_name))
+			ORDER BY lower(s.author_name), s.line
+			RETURNING id
+		)
+		SELECT count(*) FROM created
+	`).Scan(&report.AuthorsCreated)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE import_books s SET author_id = a.id
+		FROM (SELECT lower(name) AS name, min(id) AS id FROM authors GROUP BY lower(name)) a
+		WHERE a.name = lower(s.author_name)
+	`)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+
+	queries = []string{
+		`INSERT INTO books (id, title, author_id, available, isbn)
+		SELECT book_id, title, author_id, FALSE, isbn FROM import_books ORDER BY line`,
+		`INSERT INTO book_copies (book_id, barcode)
+		SELECT ic.book_id, ic.barcode FROM import_copies ic JOIN import_books s ON s.line = ic.line`,
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return nil, dbError(err, nil)
+		}
+	}
+
+	var accepted []importedLine
+	err = sqlx.SelectContext(ctx, tx, &accepted, `SELECT line, book_id FROM import_books ORDER BY line`)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	for _, a := range accepted {
+		report.Lines = append(report.Lines, domain.ImportLine{Line: a.Line, Status: domain.ImportAccepted, BookID: a.BookID})
+	}
+
+	return report, nil
+}
+
+func copyImportRows(ctx context.Context, tx *sqlx.Tx, rows []domain.ImportRow) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("import_books", "line", "title", "author_name", "isbn", "copies"))
+	if err != nil {
+		return dbError(err, nil)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row.Line, row.Title, row.AuthorName, row.ISBN, row.Copies); err != nil {
+			return dbError(err, nil)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return dbError(err, nil)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"library/internal/domain"
+	"library/internal/testdb"
+	"testing"
+)
+
+// TestImportBooksTakenBarcode - строка, чей сгенерированный штрихкод уже занят, отклоняется и не создаёт автора,
+// остальные загружаются
+func TestImportBooksTakenBarcode(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+
+	// книга 1 с экземпляром, которому вручную дали штрихкод будущей книги 2
+	seed := []string{
+		`INSERT INTO authors (name) VALUES ('Author')`,
+		`INSERT INTO books (title, author_id) VALUES ('Existing', 1)`,
+		`INSERT INTO book_copies (book_id, barcode) VALUES (1, 'B000000002')`,
+	}
+	for _, query := range seed {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows := []domain.ImportRow{
+		{Line: 2, Title: "Collides", AuthorName: "Rejected Author", Copies: 1},
+		{Line: 3, Title: "Imported", AuthorName: "Author", Copies: 2},
+	}
+	var report *domain.ImportReport
+	err := NewTxManager(db).Do(ctx, func(ctx context.Context) (err error) {
+		report, err = NewImportRepository(db).ImportBooks(ctx, rows)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ImportBooks() error = %v, want the colliding line rejected", err)
+	}
+
+	status := map[int]string{}
+	for _, line := range report.Lines {
+		status[line.Line] = line.Status
+	}
+	if status[2] != domain.ImportRejected || status[3] != domain.ImportAccepted {
+		t.Fatalf("line statuses %v, want 2 rejected and 3 accepted", status)
+	}
+
+	var authors int
+	if err := db.Get(&authors, `SELECT count(*) FROM authors WHERE name = 'Rejected Author'`); err != nil {
+		t.Fatal(err)
+	}
+	if authors != 0 || report.AuthorsCreated != 0 {
+		t.Fatalf("rejected line created %d authors, report counts %d, want none", authors, report.AuthorsCreated)
+	}
+
+	var copies int
+	if err := db.Get(&copies, `SELECT count(*) FROM book_copies c JOIN books b ON b.id = c.book_id WHERE b.title = 'Imported'`); err != nil {
+		t.Fatal(err)
+	}
+	if copies != 2 {
+		t.Fatalf("imported book has %d copies, want 2", copies)
+	}
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"library/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Ledgerer interface {
+	AddEntry(ctx context.Context, entry *domain.LedgerEntry) error
+	GetBalance(ctx context.Context, userID int) (int64, error)
+	ListByUser(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.LedgerEntry], error)
+}
+
+type LedgerRepository struct {
+	db *sqlx.DB
+}
+
+func NewLedgerRepository(db *sqlx.DB) Ledgerer {
+	return &LedgerRepository{db: db}
+}
+
+func (r LedgerRepository) AddEntry(ctx context.Context, entry *domain.LedgerEntry) error {
+	query := `
+		INSERT INTO user_ledger (user_id, rental_id, kind, amount, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return conn(ctx, r.db).QueryRowxContext(
+		ctx,
+		query,
+		entry.UserID,
+		entry.RentalID,
+		entry.Kind,
+		entry.Amount,
+		entry.Note,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// GetBalance - задолженность читателя: штрафы минус оплаты и списания
+func (r LedgerRepository) GetBalance(ctx context.Context, userID int) (int64, error) {
+	var balance int64
+	query := `
+		SELECT COALESCE(SUM(CASE WHEN kind = 'fine' THEN amount ELSE -amount END), 0)
+		FROM user_ledger
+		WHERE user_id = $1
+	`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &balance, query, userID)
+	if err != nil {
+		return 0, dbError(err, nil)
+	}
+	return balance, nil
+}
+
+// ListByUser - страница журнала счёта читателя, по умолчанию в порядке записи
+func (r LedgerRepository) ListByUser(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.LedgerEntry], error) {
+	entries := listQuery[domain.LedgerEntry]{
+		base:     `SELECT l.id, l.user_id, l.rental_id, l.kind, l.amount, l.note, l.created_at FROM user_ledger l`,
+		where:    []string{"l.user_id = $1"},
+		args:     []interface{}{userID},
+		idColumn: "l.id",
+		id:       func(e domain.LedgerEntry) int { return e.ID },
+		sorts: map[string]sortField[domain.LedgerEntry]{
+			"id":         {listField{"l.id", kindInt}, func(e domain.LedgerEntry) interface{} { return e.ID }},
+			"amount":     {listField{"l.amount", kindInt}, func(e domain.LedgerEntry) interface{} { return e.Amount }},
+			"created_at": {listField{"l.created_at", kindTime}, func(e domain.LedgerEntry) interface{} { return e.CreatedAt }},
+		},
+		defaultSort: "created_at",
+		filters: map[string]listField{
+			"kind":       {"l.kind", kindString},
+			"amount":     {"l.amount", kindInt},
+			"rental_id":  {"l.rental_id", kindInt},
+			"created_at": {"l.created_at", kindTime},
+		},
+	}
+	return listPage(ctx, conn(ctx, r.db), entries, req)
+}
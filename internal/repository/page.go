@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"library/internal/domain"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// fieldKind - тип значения поля списка, по нему разбираются фильтры и курсор
+type fieldKind int
+
+const (
+	kindInt fieldKind = iota
+	kindBool
+	kindString
+	kindTime
+)
+
+var kindOps = map[fieldKind][]string{
+	kindInt:    {domain.FilterEq, domain.FilterNe, domain.FilterLt, domain.FilterLte, domain.FilterGt, domain.FilterGte},
+	kindBool:   {domain.FilterEq, domain.FilterNe},
+	kindString: {domain.FilterEq, domain.FilterNe, domain.FilterLike},
+	kindTime:   {domain.FilterEq, domain.FilterNe, domain.FilterLt, domain.FilterLte, domain.FilterGt, domain.FilterGte},
+}
+
+var opSQL = map[string]string{
+	domain.FilterEq:   "=",
+	domain.FilterNe:   "<>",
+	domain.FilterLt:   "<",
+	domain.FilterLte:  "<=",
+	domain.FilterGt:   ">",
+	domain.FilterGte:  ">=",
+	domain.FilterLike: "ILIKE",
+}
+
+// listField - поле, разрешённое в фильтре списка
+type listField struct {
+	column string
+	kind   fieldKind
+}
+
+// sortField - поле сортировки; value достаёт ключ из строки для курсора следующей страницы.
+// Колонка сортировки не должна допускать NULL, иначе сравнение по ключу теряет строки.
+type sortField[T any] struct {
+	listField
+	value func(item T) interface{}
+}
+
+// listQuery - постраничный список: базовый запрос без WHERE, постоянные условия и белые списки полей
+type listQuery[T any] struct {
+	base        string
+	where       []string
+	args        []interface{}
+	idColumn    string
+	id          func(item T) int
+	sorts       map[string]sortField[T]
+	defaultSort string
+	filters     map[string]listField
+}
+
+// cursor - позиция последней строки страницы; сортировка запоминается, чтобы курсор не применили к другому порядку
+type cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// listPage выбирает страницу по ключу (sort, id) после курсора: глубина страницы не влияет на стоимость запроса
+func listPage[T any](ctx context.Context, db sqlx.ExtContext, lq listQuery[T], req domain.PageRequest) (*domain.Page[T], error) {
+	sortName := req.Sort
+	if sortName == "" {
+		sortName = lq.defaultSort
+	}
+	key, ok := lq.sorts[sortName]
+	if !ok {
+		return nil, invalidList("sort", "must be one of "+strings.Join(fieldNames(lq.sorts), ", "))
+	}
+
+	where := append([]string(nil), lq.where...)
+	args := append([]interface{}(nil), lq.args...)
+	for _, f := range req.Filters {
+		cond, arg, err := lq.filter(f, len(args)+1)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, cond)
+		args = append(args, arg)
+	}
+	conds, filtered := len(where), len(args)
+
+	op, dir := ">", "ASC"
+	if req.Desc {
+		op, dir = "<", "DESC"
+	}
+
+	if req.After != "" {
+		c, err := decodeCursor(req.After)
+		if err != nil || c.Sort != sortName || c.Desc != req.Desc {
+			return nil, invalidList("after", "cursor does not match this list and sort")
+		}
+		if key.column == lq.idColumn {
+			args = append(args, c.ID)
+			where = append(where, fmt.Sprintf("%s %s $%d", lq.idColumn, op, len(args)))
+		} else {
+			value, err := parseValue(key.kind, c.Value)
+			if err != nil {
+				return nil, invalidList("after", "cursor does not match this list and sort")
+			}
+			args = append(args, value, c.ID)
+			where = append(where, fmt.Sprintf("(%s, %s) %s ($%d, $%d)", key.column, lq.idColumn, op, len(args)-1, len(args)))
+		}
+	}
+
+	order := key.column + " " + dir
+	if key.column != lq.idColumn {
+		order += ", " + lq.idColumn + " " + dir
+	}
+
+	limit := pageLimit(req.Limit)
+	args = append(args, limit+1)
+	query := lq.base + whereClause(where) + ` ORDER BY ` + order + fmt.Sprintf(` LIMIT $%d`, len(args))
+
+	items := []T{}
+	if err := sqlx.SelectContext(ctx, db, &items, query, args...); err != nil {
+		return nil, dbError(err, nil)
+	}
+
+	page := &domain.Page[T]{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeCursor(cursor{
+			Sort:  sortName,
+			Desc:  req.Desc,
+			Value: formatValue(key.value(last)),
+			ID:    lq.id(last),
+		})
+	}
+
+	estimate, err := estimateRows(ctx, db, lq.base+whereClause(where[:conds]), args[:filtered])
+	if err != nil {
+		return nil, err
+	}
+	page.TotalEstimate = estimate
+
+	return page, nil
+}
+
+// filter переводит условие запроса в SQL; поле и оператор проверяются по белому списку
+func (lq listQuery[T]) filter(f domain.Filter, n int) (string, interface{}, error) {
+	field, ok := lq.filters[f.Field]
+	if !ok {
+		return "", nil, invalidList("filter", fmt.Sprintf("unknown field %q, must be one of %s", f.Field, strings.Join(fieldNames(lq.filters), ", ")))
+	}
+	if !slices.Contains(kindOps[field.kind], f.Op) {
+		return "", nil, invalidList("filter", fmt.Sprintf("operator %q is not supported for %s", f.Op, f.Field))
+	}
+
+	if f.Op == domain.FilterLike {
+		return fmt.Sprintf("%s ILIKE $%d", field.column, n), "%" + escapeLike(f.Value) + "%", nil
+	}
+	value, err := parseValue(field.kind, f.Value)
+	if err != nil {
+		return "", nil, invalidList("filter", fmt.Sprintf("%s: %v", f.Field, err))
+	}
+	return fmt.Sprintf("%s %s $%d", field.column, opSQL[f.Op], n), value, nil
+}
+
+// estimateRows - оценка числа строк из плана запроса; точный COUNT(*) по большим таблицам слишком дорог
+func estimateRows(ctx context.Context, db sqlx.ExtContext, query string, args []interface{}) (int64, error) {
+	var raw []byte
+	if err := db.QueryRowxContext(ctx, `EXPLAIN (FORMAT JSON) `+query, args...).Scan(&raw); err != nil {
+		return 0, dbError(err, nil)
+	}
+
+	var plans []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &plans); err != nil || len(plans) == 0 {
+		return 0, fmt.Errorf("unexpected explain output: %w", err)
+	}
+	return int64(math.Round(plans[0].Plan.Rows)), nil
+}
+
+func pageLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return domain.DefaultPageLimit
+	case limit > domain.MaxPageLimit:
+		return domain.MaxPageLimit
+	}
+	return limit
+}
+
+func whereClause(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+	return ` WHERE ` + strings.Join(where, " AND ")
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	return c, json.Unmarshal(data, &c)
+}
+
+// parseValue разбирает значение фильтра или курсора по типу поля
+func parseValue(kind fieldKind, s string) (interface{}, error) {
+	switch kind {
+	case kindInt:
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return v, nil
+	case kindBool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return v, nil
+	case kindTime:
+		if v, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return v, nil
+		}
+		v, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			return nil, errors.New("must be a date or RFC 3339 timestamp")
+		}
+		return v, nil
+	}
+	return s, nil
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE в пользовательском вводе
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func invalidList(field, message string) error {
+	return &domain.ErrInvalidFields{Fields: []domain.FieldError{{Field: field, Message: message}}}
+}
+
+func fieldNames[V any](fields map[string]V) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"library/internal/domain"
+	"library/internal/testdb"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var rowCounts = []int{1, 100, 1000}
+
+// seedLibrary - n авторов с книгой, экземпляром и читателем, у которого этот экземпляр на руках
+func seedLibrary(tb testing.TB, db *sqlx.DB, n int) {
+	tb.Helper()
+	queries := []string{
+		`INSERT INTO authors (name) SELECT 'Author ' || i FROM generate_series(1, $1) i`,
+		`INSERT INTO books (title, author_id) SELECT 'Book ' || i, i FROM generate_series(1, $1) i`,
+		`INSERT INTO book_copies (book_id, barcode, available) SELECT i, 'T' || i, FALSE FROM generate_series(1, $1) i`,
+		`INSERT INTO users (name, email) SELECT 'User ' || i, 'user' || i || '@example.com' FROM generate_series(1, $1) i`,
+		`INSERT INTO book_rental (book_id, copy_id, user_id, rental_date, due_date)
+		 SELECT i, i, i, now(), now() + interval '14 days' FROM generate_series(1, $1) i`,
+	}
+	for _, query := range queries {
+		if _, err := db.Exec(query, n); err != nil {
+			tb.Fatalf("seed %d rows: %v", n, err)
+		}
+	}
+}
+
+// queryCountCases - списки, которые подгружают связанные строки; число запросов не должно зависеть от числа строк
+var queryCountCases = []struct {
+	name string
+	run  func(ctx context.Context, db *sqlx.DB, n int) (int, error)
+}{
+	{"AuthorRepository.List", func(ctx context.Context, db *sqlx.DB, n int) (int, error) {
+		page, err := NewAuthorRepository(db).List(ctx, domain.PageRequest{Limit: domain.MaxPageLimit})
+		if err != nil {
+			return 0, err
+		}
+		return loaded(page.Items, func(a domain.Author) int { return len(a.Books) }), nil
+	}},
+	{"AuthorRepository.GetTopAuthors", func(ctx context.Context, db *sqlx.DB, n int) (int, error) {
+		top, err := NewAuthorRepository(db).GetTopAuthors(ctx, n)
+		if err != nil {
+			return 0, err
+		}
+		return loaded(top, func(a *domain.AuthorWithRentCount) int { return len(a.Author.Books) }), nil
+	}},
+	{"UserRepository.List", func(ctx context.Context, db *sqlx.DB, n int) (int, error) {
+		page, err := NewUserRepository(db).List(ctx, domain.PageRequest{Limit: domain.MaxPageLimit})
+		if err != nil {
+			return 0, err
+		}
+		return loaded(page.Items, func(u domain.User) int { return len(u.RentedBooks) }), nil
+	}},
+}
+
+// loaded - сколько строк получили связанные записи; без них тест не отличил бы пакетную загрузку от пустой
+func loaded[T any](items []T, related func(T) int) int {
+	n := 0
+	for _, item := range items {
+		if related(item) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func TestListQueryCount(t *testing.T) {
+	for _, tc := range queryCountCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var want int64 = -1
+			for _, n := range rowCounts {
+				db, counter := testdb.OpenCounting(t)
+				seedLibrary(t, db, n)
+				counter.Reset()
+
+				withRelated, err := tc.run(context.Background(), db, n)
+				if err != nil {
+					t.Fatalf("%d rows: %v", n, err)
+				}
+				if withRelated == 0 {
+					t.Fatalf("%d rows: related rows were not loaded", n)
+				}
+				got := counter.Count()
+				if want < 0 {
+					want = got
+				}
+				if got != want {
+					t.Errorf("%d rows: %d queries, want %d as for %d rows", n, got, want, rowCounts[0])
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkListQueryCount(b *testing.B) {
+	for _, tc := range queryCountCases {
+		for _, n := range rowCounts {
+			b.Run(fmt.Sprintf("%s/rows=%d", tc.name, n), func(b *testing.B) {
+				db, counter := testdb.OpenCounting(b)
+				seedLibrary(b, db, n)
+				ctx := context.Background()
+
+				counter.Reset()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := tc.run(ctx, db, n); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(counter.Count())/float64(b.N), "queries/op")
+			})
+		}
+	}
+}
@@ -2,15 +2,26 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"library/internal/domain"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Rentaler interface {
-	RentBook(ctx context.Context, bookID, userID int) error
-	ReturnBook(ctx context.Context, bookId int) error
+	RentBook(ctx context.Context, bookID, copyID, userID int, dueDate time.Time) error
+	ReturnCopy(ctx context.Context, copyID int) (*domain.BookRental, error)
+	GetActiveByCopy(ctx context.Context, copyID int) (*domain.BookRental, error)
+	GetActiveByBookAndUser(ctx context.Context, bookID, userID int) (*domain.BookRental, error)
+	GetByID(ctx context.Context, id int) (*domain.BookRental, error)
+	ListActiveBooksByUser(ctx context.Context, userID int) ([]domain.Book, error)
+	ListLoansByUser(ctx context.Context, userID int, activeOnly bool, req domain.PageRequest) (*domain.Page[domain.Loan], error)
+	Renew(ctx context.Context, id int, dueDate time.Time) (*domain.BookRental, error)
+	ListOverdue(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error)
+	ListOverdueByUser(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error)
 }
 
 type RentalRepository struct {
@@ -21,35 +32,245 @@ func NewRentalRepository(db *sqlx.DB) Rentaler {
 	return &RentalRepository{db: db}
 }
 
-func (r RentalRepository) RentBook(ctx context.Context, bookID, userID int) error {
+// RentBook выдаёт экземпляр copyID; unique_book_rental не даёт читателю взять два экземпляра одной книги
+func (r RentalRepository) RentBook(ctx context.Context, bookID, copyID, userID int, dueDate time.Time) error {
 	uRental := domain.UniqueBookRental{
 		BookID: bookID,
 		UserID: userID,
 	}
-	queryBookRental := `INSERT INTO book_rental (book_id, user_id, rental_date) VALUES ($1, $2,$3)`
-	_, err := r.db.ExecContext(ctx, queryBookRental, bookID, userID, time.Now())
+	queryBookRental := `INSERT INTO book_rental (book_id, copy_id, user_id, rental_date, due_date) VALUES ($1, $2, $3, $4, $5)`
+	_, err := conn(ctx, r.db).ExecContext(ctx, queryBookRental, bookID, copyID, userID, time.Now(), dueDate)
 	if err != nil {
-		return err
+		return rentalConflict(err, bookID, userID)
 	}
 
 	queryUnique := `INSERT INTO unique_book_rental (book_id, user_id) VALUES(:book_id,:user_id)`
-	_, err = r.db.NamedExecContext(ctx, queryUnique, uRental)
+	_, err = sqlx.NamedExecContext(ctx, conn(ctx, r.db), queryUnique, uRental)
 	if err != nil {
-		return err
+		return rentalConflict(err, bookID, userID)
 	}
 
 	return nil
 }
-func (r RentalRepository) ReturnBook(ctx context.Context, bookId int) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM unique_book_rental WHERE book_id=$1", bookId)
+
+// ReturnCopy закрывает активную выдачу экземпляра
+func (r RentalRepository) ReturnCopy(ctx context.Context, copyID int) (*domain.BookRental, error) {
+	var rental domain.BookRental
+	query := `
+		UPDATE book_rental SET return_date = $1
+		WHERE copy_id = $2 AND return_date IS NULL
+		RETURNING id, book_id, copy_id, user_id, rental_date, due_date, return_date, renewals, created_at
+	`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &rental, query, time.Now(), copyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &domain.ErrRentalNotFound{CopyID: copyID}
+	}
 	if err != nil {
-		return err
+		return nil, dbError(err, nil)
 	}
-	returnDate := time.Now()
-	_, err = r.db.ExecContext(ctx, "UPDATE book_rental SET return_date = $1 WHERE book_id=$2 AND return_date IS NULL", returnDate, bookId)
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, `
+		DELETE FROM unique_book_rental u
+		WHERE u.book_id = $1 AND u.user_id = $2
+			AND NOT EXISTS (
+				SELECT 1 FROM book_rental r
+				WHERE r.book_id = u.book_id AND r.user_id = u.user_id AND r.return_date IS NULL
+			)
+	`, rental.BookID, rental.UserID)
 	if err != nil {
-		return err
+		return nil, dbError(err, nil)
 	}
 
-	return nil
+	return &rental, nil
+}
+
+func (r RentalRepository) GetActiveByCopy(ctx context.Context, copyID int) (*domain.BookRental, error) {
+	var rental domain.BookRental
+	query := `
+		SELECT id, book_id, copy_id, user_id, rental_date, due_date, return_date, renewals, created_at,
+			due_date < now() AS overdue
+		FROM book_rental
+		WHERE copy_id = $1 AND return_date IS NULL
+	`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &rental, query, copyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &domain.ErrRentalNotFound{CopyID: copyID}
+	}
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+
+	return &rental, nil
+}
+
+// GetActiveByBookAndUser - экземпляр книги, который сейчас на руках у читателя
+func (r RentalRepository) GetActiveByBookAndUser(ctx context.Context, bookID, userID int) (*domain.BookRental, error) {
+	var rental domain.BookRental
+	query := `
+		SELECT id, book_id, copy_id, user_id, rental_date, due_date, return_date, renewals, created_at,
+			due_date < now() AS overdue
+		FROM book_rental
+		WHERE book_id = $1 AND user_id = $2 AND return_date IS NULL
+		ORDER BY id
+		LIMIT 1
+	`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &rental, query, bookID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &domain.ErrRentalNotFound{BookID: bookID}
+	}
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+
+	return &rental, nil
+}
+
+func (r RentalRepository) GetByID(ctx context.Context, id int) (*domain.BookRental, error) {
+	var rental domain.BookRental
+	query := `
+		SELECT id, book_id, copy_id, user_id, rental_date, due_date, return_date, renewals, created_at,
+			(return_date IS NULL AND due_date < now()) AS overdue
+		FROM book_rental
+		WHERE id = $1
+		FOR UPDATE
+	`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &rental, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &domain.ErrRentalNotFound{RentalID: id}
+	}
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+
+	return &rental, nil
+}
+
+func (r RentalRepository) Renew(ctx context.Context, id int, dueDate time.Time) (*domain.BookRental, error) {
+	var rental domain.BookRental
+	query := `
+		UPDATE book_rental SET due_date = $1, renewals = renewals + 1
+		WHERE id = $2 AND return_date IS NULL
+		RETURNING id, book_id, copy_id, user_id, rental_date, due_date, return_date, renewals, created_at
+	`
+	err := sqlx.GetContext(ctx, conn(ctx, r.db), &rental, query, dueDate, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &domain.ErrRentalNotFound{RentalID: id}
+	}
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+
+	return &rental, nil
+}
+
+// ListActiveBooksByUser - книги, которые сейчас на руках у читателя
+func (r RentalRepository) ListActiveBooksByUser(ctx context.Context, userID int) ([]domain.Book, error) {
+	var books []domain.Book
+	query := `
+		SELECT b.id, b.title, b.author_id, b.available, b.genre, b.loan_period_days,
+			b.isbn, b.publication_year, b.created_at
+		FROM book_rental r
+		JOIN books b ON b.id = r.book_id
+		WHERE r.user_id = $1 AND r.return_date IS NULL
+	`
+	err := sqlx.SelectContext(ctx, conn(ctx, r.db), &books, query, userID)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	return books, nil
+}
+
+// ListLoansByUser - выдачи читателя с книгой и автором, по умолчанию от новых к старым
+func (r RentalRepository) ListLoansByUser(ctx context.Context, userID int, activeOnly bool, req domain.PageRequest) (*domain.Page[domain.Loan], error) {
+	loans := listQuery[domain.Loan]{
+		base: `
+		SELECT r.id, r.book_id, r.copy_id, r.user_id, r.rental_date, r.due_date, r.return_date, r.renewals, r.created_at,
+			(r.return_date IS NULL AND r.due_date < now()) AS overdue,
+			b.id AS "book.id", b.title AS "book.title", b.author_id AS "book.author_id",
+			b.available AS "book.available", b.genre AS "book.genre",
+			b.loan_period_days AS "book.loan_period_days", b.isbn AS "book.isbn",
+			b.publication_year AS "book.publication_year", b.created_at AS "book.created_at",
+			a.id AS "book.author.id", a.name AS "book.author.name",
+			COALESCE(a.biography, '') AS "book.author.biography", a.created_at AS "book.author.created_at"
+		FROM book_rental r
+		JOIN books b ON b.id = r.book_id
+		JOIN authors a ON a.id = b.author_id
+		`,
+		where:    []string{"r.user_id = $1"},
+		args:     []interface{}{userID},
+		idColumn: "r.id",
+		id:       func(l domain.Loan) int { return l.ID },
+		sorts: map[string]sortField[domain.Loan]{
+			"id":          {listField{"r.id", kindInt}, func(l domain.Loan) interface{} { return l.ID }},
+			"rental_date": {listField{"r.rental_date", kindTime}, func(l domain.Loan) interface{} { return l.RentalDate }},
+			"due_date":    {listField{"r.due_date", kindTime}, func(l domain.Loan) interface{} { return l.DueDate }},
+		},
+		defaultSort: "rental_date",
+		filters: map[string]listField{
+			"book_id":     {"r.book_id", kindInt},
+			"copy_id":     {"r.copy_id", kindInt},
+			"author_id":   {"b.author_id", kindInt},
+			"genre":       {"b.genre", kindString},
+			"rental_date": {"r.rental_date", kindTime},
+			"due_date":    {"r.due_date", kindTime},
+		},
+	}
+	if activeOnly {
+		loans.where = append(loans.where, "r.return_date IS NULL")
+	}
+	if req.Sort == "" {
+		req.Desc = true
+	}
+	return listPage(ctx, conn(ctx, r.db), loans, req)
+}
+
+// overdueList - просроченные аренды с книгой и читателем
+func overdueList() listQuery[domain.OverdueRental] {
+	return listQuery[domain.OverdueRental]{
+		base: `
+		SELECT r.id, r.book_id, r.copy_id, r.user_id, r.rental_date, r.due_date, r.return_date, r.renewals, r.created_at,
+			TRUE AS overdue, b.title AS book_title, u.name AS user_name, u.email AS user_email
+		FROM book_rental r
+		JOIN books b ON b.id = r.book_id
+		JOIN users u ON u.id = r.user_id
+		`,
+		where:    []string{"r.return_date IS NULL", "r.due_date < now()"},
+		idColumn: "r.id",
+		id:       func(o domain.OverdueRental) int { return o.ID },
+		sorts: map[string]sortField[domain.OverdueRental]{
+			"id":          {listField{"r.id", kindInt}, func(o domain.OverdueRental) interface{} { return o.ID }},
+			"due_date":    {listField{"r.due_date", kindTime}, func(o domain.OverdueRental) interface{} { return o.DueDate }},
+			"rental_date": {listField{"r.rental_date", kindTime}, func(o domain.OverdueRental) interface{} { return o.RentalDate }},
+		},
+		defaultSort: "due_date",
+		filters: map[string]listField{
+			"book_id":  {"r.book_id", kindInt},
+			"user_id":  {"r.user_id", kindInt},
+			"due_date": {"r.due_date", kindTime},
+		},
+	}
+}
+
+func (r RentalRepository) ListOverdue(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error) {
+	return listPage(ctx, conn(ctx, r.db), overdueList(), req)
+}
+
+func (r RentalRepository) ListOverdueByUser(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error) {
+	overdue := overdueList()
+	overdue.where = append(overdue.where, "r.user_id = $1")
+	overdue.args = []interface{}{userID}
+	return listPage(ctx, conn(ctx, r.db), overdue, req)
+}
+
+// rentalConflict превращает нарушение уникальности выдачи в ошибку: экземпляр уже выдан,
+// либо у читателя уже есть другой экземпляр этой книги
+func rentalConflict(err error, bookID, userID int) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation {
+		if pqErr.Table == "unique_book_rental" {
+			return &domain.ErrAlreadyRented{BookID: bookID, UserID: userID}
+		}
+		return &domain.ErrBookNotAvailable{BookID: bookID}
+	}
+	return dbError(err, nil)
 }
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"library/internal/domain"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Searcher interface {
+	Search(ctx context.Context, query domain.SearchQuery) ([]domain.SearchHit, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error)
+}
+
+type SearchRepository struct {
+	db *sqlx.DB
+}
+
+func NewSearchRepository(db *sqlx.DB) Searcher {
+	return &SearchRepository{db: db}
+}
+
+// searchColumns - колонка tsvector для каждой конфигурации поиска, см. миграцию 000011
+var searchColumns = map[string]string{
+	domain.SearchEnglish: "search_en",
+	domain.SearchRussian: "search_ru",
+}
+
+// querySearch - книги и авторы одним ранжированным списком; %[1]s - колонка tsvector выбранной конфигурации.
+// ts_headline не экранирует текст, поэтому совпадения отмечаются управляющими символами chr(2) и chr(3),
+// а HTML собирается уже после экранирования в markHeadline; сами эти символы из текста убираются
+const querySearch = `
+	WITH q AS (SELECT websearch_to_tsquery($1::regconfig, $2) AS query)
+	SELECT 'book' AS kind, b.id, b.title, a.id AS author_id, a.name AS author_name,
+		ts_rank(b.%[1]s, q.query) AS rank,
+		ts_headline($1::regconfig, translate(b.title, chr(2) || chr(3), ''), q.query,
+			'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', HighlightAll=true') AS headline
+	FROM books b
+	JOIN authors a ON a.id = b.author_id, q
+	WHERE b.%[1]s @@ q.query
+	UNION ALL
+	SELECT 'author' AS kind, a.id, a.name AS title, a.id AS author_id, a.name AS author_name,
+		ts_rank(a.%[1]s, q.query) AS rank,
+		ts_headline($1::regconfig, translate(a.name || '. ' || COALESCE(a.biography, ''), chr(2) || chr(3), ''), q.query,
+			'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=2, MaxWords=30, MinWords=10') AS headline
+	FROM authors a, q
+	WHERE a.%[1]s @@ q.query
+	ORDER BY rank DESC, kind, id
+	LIMIT $3
+`
+
+func (r *SearchRepository) Search(ctx context.Context, query domain.SearchQuery) ([]domain.SearchHit, error) {
+	column, ok := searchColumns[query.Language]
+	if !ok {
+		return nil, invalidList("lang", "unsupported search language")
+	}
+
+	hits := []domain.SearchHit{}
+	err := sqlx.SelectContext(ctx, conn(ctx, r.db), &hits, fmt.Sprintf(querySearch, column),
+		query.Language, query.Text, pageLimit(query.Limit))
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	for i := range hits {
+		hits[i].Headline = markHeadline(hits[i].Headline)
+	}
+	return hits, nil
+}
+
+var headlineMarks = strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>")
+
+// markHeadline экранирует фрагмент как HTML и только затем превращает метки совпадений в теги <mark>
+func markHeadline(headline string) string {
+	return headlineMarks.Replace(html.EscapeString(headline))
+}
+
+// querySuggest - автодополнение по триграммам: оператор <% находит введённый текст внутри названия даже с опечатками,
+// совпадение с началом названия поднимается выше; оба условия используют GIN индексы из миграции 000012
+const querySuggest = `
+	SELECT kind, id, text, score
+	FROM (
+		SELECT 'book' AS kind, b.id, b.title AS text,
+			word_similarity($1, b.title) + CASE WHEN b.title ILIKE $2 THEN 1 ELSE 0 END AS score
+		FROM books b
+		WHERE $1 <% b.title OR b.title ILIKE $2
+		UNION ALL
+		SELECT 'author' AS kind, a.id, a.name AS text,
+			word_similarity($1, a.name) + CASE WHEN a.name ILIKE $2 THEN 1 ELSE 0 END AS score
+		FROM authors a
+		WHERE $1 <% a.name OR a.name ILIKE $2
+	) s
+	ORDER BY score DESC, length(text), id
+	LIMIT $3
+`
+
+func (r *SearchRepository) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error) {
+	suggestions := []domain.Suggestion{}
+	err := sqlx.SelectContext(ctx, conn(ctx, r.db), &suggestions, querySuggest, prefix, escapeLike(prefix)+"%", limit)
+	if err != nil {
+		return nil, dbError(err, nil)
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"library/internal/domain"
+	"library/internal/testdb"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestMarkHeadline(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"\x02Война\x03 и мир", "<mark>Война</mark> и мир"},
+		{"<script>alert(1)</script> \x02rings\x03", "&lt;script&gt;alert(1)&lt;/script&gt; <mark>rings</mark>"},
+		{"Tom & \"Jerry\" \x02it's\x03", "Tom &amp; &#34;Jerry&#34; <mark>it&#39;s</mark>"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := markHeadline(tt.in); got != tt.want {
+			t.Errorf("markHeadline(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// seedTitles - автор и книги с заданными названиями, id книг идут по порядку с 1
+func seedTitles(tb testing.TB, db *sqlx.DB, author string, titles ...string) {
+	tb.Helper()
+	if _, err := db.Exec(`INSERT INTO authors (name) VALUES ($1)`, author); err != nil {
+		tb.Fatal(err)
+	}
+	for _, title := range titles {
+		if _, err := db.Exec(`INSERT INTO books (title, author_id) VALUES ($1, 1)`, title); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+	}{
+		{"misspelled prefix", "Harry Pottr", "Harry Potter and the Philosopher's Stone"},
+		{"misspelled prefix in lower case", "harry poter", "Harry Potter and the Philosopher's Stone"},
+		// оба названия содержат слово целиком, но совпадение с началом названия выше
+		{"prefix boost", "war", "War and Peace"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testdb.Open(t)
+			seedTitles(t, db, "Nobody",
+				"The Art of War", "War and Peace", "Harry Hole", "Pottery for Beginners", "Harry Potter and the Philosopher's Stone")
+
+			suggestions, err := NewSearchRepository(db).Suggest(context.Background(), tt.prefix, domain.DefaultSuggestLimit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(suggestions) == 0 || suggestions[0].Kind != "book" || suggestions[0].Text != tt.want {
+				t.Fatalf("Suggest(%q) = %+v, want %q first", tt.prefix, suggestions, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRussianStemming(t *testing.T) {
+	db := testdb.Open(t)
+	seedTitles(t, db, "Лев Толстой", "Анна Каренина", "Война и мир", "Война после войны")
+	repo := NewSearchRepository(db)
+	ctx := context.Background()
+
+	hits, err := repo.Search(ctx, domain.SearchQuery{Text: "войны", Language: domain.SearchRussian, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// обе формы слова сводятся к одной основе, поэтому книга с двумя совпадениями ранжируется выше
+	if len(hits) != 2 || hits[0].Title != "Война после войны" || hits[1].Title != "Война и мир" {
+		t.Fatalf("Search() = %+v, want \"Война после войны\" and then \"Война и мир\"", hits)
+	}
+	if hits[0].Rank <= hits[1].Rank {
+		t.Errorf("rank %v is not above %v", hits[0].Rank, hits[1].Rank)
+	}
+	if want := "<mark>Война</mark> и мир"; hits[1].Headline != want {
+		t.Errorf("headline = %q, want %q", hits[1].Headline, want)
+	}
+
+	// английская конфигурация не знает русских словоформ
+	hits, err = repo.Search(ctx, domain.SearchQuery{Text: "войны", Language: domain.SearchEnglish, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 || hits[0].Title != "Война после войны" {
+		t.Fatalf("english Search() = %+v, want only the exact word form", hits)
+	}
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"library/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultTxAttempts = 5
+	txRetryBackoff    = 20 * time.Millisecond
+
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+)
+
+type txKey struct{}
+
+// TxManager - единица работы: все вызовы репозиториев внутри fn выполняются в одной транзакции
+type TxManager interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type Transactor struct {
+	db       *sqlx.DB
+	opts     *sql.TxOptions
+	attempts int
+}
+
+func NewTxManager(db *sqlx.DB) TxManager {
+	return &Transactor{
+		db:       db,
+		opts:     &sql.TxOptions{Isolation: sql.LevelSerializable},
+		attempts: defaultTxAttempts,
+	}
+}
+
+// Do выполняет fn в транзакции и повторяет её при ошибках сериализации Postgres.
+// Если в ctx уже есть транзакция, fn присоединяется к ней.
+func (t *Transactor) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 1; attempt <= t.attempts; attempt++ {
+		err = t.run(ctx, fn)
+		if !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * txRetryBackoff):
+		}
+	}
+
+	// конкуренция не разрешилась за отведённые попытки, клиент может повторить запрос позже
+	return &domain.ErrStorageUnavailable{Err: err}
+}
+
+func (t *Transactor) run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, err := t.db.BeginTxx(ctx, t.opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == pqSerializationFailure || pqErr.Code == pqDeadlockDetected
+}
+
+// conn возвращает транзакцию из контекста, либо соединение с базой
+func conn(ctx context.Context, db *sqlx.DB) sqlx.ExtContext {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return db
+}
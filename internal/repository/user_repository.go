@@ -6,12 +6,15 @@ import (
 	"library/internal/domain"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Userer interface {
 	Create(ctx context.Context, user *domain.User) error
 	GetByID(ctx context.Context, id int) (*domain.User, error)
-	GetAllUsers(ctx context.Context) ([]*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	List(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.User], error)
+	Update(ctx context.Context, user *domain.User) error
 	Delete(ctx context.Context, id int) error
 }
 
@@ -24,10 +27,20 @@ func NewUserRepository(db *sqlx.DB) Userer {
 }
 
 func (u UserRepository) Create(ctx context.Context, user *domain.User) error {
-	query := `INSERT INTO users (name, email, created_at) VALUES ($1, $2,$3) RETURNING id`
-	err := u.db.QueryRowContext(ctx, query, user.Name, user.Email, user.CreatedAt).Scan(&user.ID)
+	query := `INSERT INTO users (name, email, role, password_hash, birth_date, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err := conn(ctx, u.db).QueryRowxContext(
+		ctx,
+		query,
+		user.Name,
+		user.Email,
+		user.Role,
+		user.PasswordHash,
+		user.BirthDate,
+		user.CreatedAt,
+	).Scan(&user.ID)
 	if err != nil {
-		return err
+		return dbError(err, nil)
 	}
 
 	return nil
@@ -35,55 +48,120 @@ func (u UserRepository) Create(ctx context.Context, user *domain.User) error {
 
 func (u UserRepository) GetByID(ctx context.Context, id int) (*domain.User, error) {
 	var user domain.User
-	query := `SELECT id, name, email, created_at FROM users WHERE id = $1`
-	err := u.db.GetContext(ctx, &user, query, id)
+	query := `SELECT id, name, email, role, birth_date, created_at,
+			  COALESCE((SELECT SUM(CASE WHEN l.kind = 'fine' THEN l.amount ELSE -l.amount END)
+			            FROM user_ledger l WHERE l.user_id = users.id), 0) AS balance
+			  FROM users WHERE id = $1`
+	err := sqlx.GetContext(ctx, conn(ctx, u.db), &user, query, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to user: %w", err)
+		return nil, dbError(err, &domain.ErrUserNotFound{UserID: id})
 	}
 
 	var rentals []domain.BookRental
-	queryActivRental := `SELECT id, book_id, user_id, rental_date, return_date, created_at
+	queryActivRental := `SELECT id, book_id, copy_id, user_id, rental_date, due_date, return_date, renewals, created_at,
+			  due_date < now() AS overdue
 			  FROM book_rental
 			  WHERE user_id = $1 AND return_date IS NULL`
 
-	err = u.db.SelectContext(ctx, &rentals, queryActivRental, id)
+	err = sqlx.SelectContext(ctx, conn(ctx, u.db), &rentals, queryActivRental, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list rentals: %w", err)
+		return nil, fmt.Errorf("failed to list rentals: %w", dbError(err, nil))
 	}
 	user.RentedBooks = append(user.RentedBooks, rentals...)
 
 	return &user, nil
 }
 
-func (u UserRepository) GetAllUsers(ctx context.Context) ([]*domain.User, error) {
-	var users []*domain.User
-	query := `SELECT * FROM users`
-	err := u.db.SelectContext(ctx, &users, query)
+// GetByEmail - пользователь вместе с хешем пароля, для входа в систему
+func (u UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var user domain.User
+	query := `SELECT id, name, email, role, password_hash, birth_date, created_at FROM users WHERE email = $1`
+	err := sqlx.GetContext(ctx, conn(ctx, u.db), &user, query, email)
+	if err != nil {
+		return nil, dbError(err, &domain.ErrUserNotFound{Email: email})
+	}
+	return &user, nil
+}
+
+// List - страница пользователей вместе со всеми их арендами
+func (u UserRepository) List(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.User], error) {
+	page, err := listPage(ctx, conn(ctx, u.db), userList(), req)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, value := range users {
-		var rentals []domain.BookRental
-		queryActivRental := `SELECT id, book_id, user_id, rental_date, return_date, created_at
+	if len(page.Items) == 0 {
+		return page, nil
+	}
+
+	ids := make([]int, 0, len(page.Items))
+	for _, user := range page.Items {
+		ids = append(ids, user.ID)
+	}
+
+	// аренды всей страницы одним запросом вместо запроса на каждого пользователя
+	var rentals []domain.BookRental
+	queryRentals := `SELECT id, book_id, copy_id, user_id, rental_date, due_date, return_date, renewals, created_at,
+			  (return_date IS NULL AND due_date < now()) AS overdue
 			  FROM book_rental
-			  WHERE user_id = $1`
+			  WHERE user_id = ANY($1)
+			  ORDER BY id`
 
-		err = u.db.SelectContext(ctx, &rentals, queryActivRental, value.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list rentals: %w", err)
-		}
-		value.RentedBooks = append(value.RentedBooks, rentals...)
+	err = sqlx.SelectContext(ctx, conn(ctx, u.db), &rentals, queryRentals, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rentals: %w", dbError(err, nil))
 	}
 
-	return users, nil
+	byUser := make(map[int][]domain.BookRental, len(ids))
+	for _, rental := range rentals {
+		byUser[rental.UserID] = append(byUser[rental.UserID], rental)
+	}
+	for i := range page.Items {
+		page.Items[i].RentedBooks = byUser[page.Items[i].ID]
+	}
+
+	return page, nil
+}
+
+func userList() listQuery[domain.User] {
+	return listQuery[domain.User]{
+		base: `SELECT u.id, u.name, u.email, u.role, u.birth_date, u.created_at,
+			  COALESCE((SELECT SUM(CASE WHEN l.kind = 'fine' THEN l.amount ELSE -l.amount END)
+			            FROM user_ledger l WHERE l.user_id = u.id), 0) AS balance
+			  FROM users u`,
+		idColumn: "u.id",
+		id:       func(u domain.User) int { return u.ID },
+		sorts: map[string]sortField[domain.User]{
+			"id":         {listField{"u.id", kindInt}, func(u domain.User) interface{} { return u.ID }},
+			"name":       {listField{"u.name", kindString}, func(u domain.User) interface{} { return u.Name }},
+			"email":      {listField{"u.email", kindString}, func(u domain.User) interface{} { return u.Email }},
+			"created_at": {listField{"u.created_at", kindTime}, func(u domain.User) interface{} { return u.CreatedAt }},
+		},
+		defaultSort: "id",
+		filters: map[string]listField{
+			"name":       {"u.name", kindString},
+			"email":      {"u.email", kindString},
+			"role":       {"u.role", kindString},
+			"birth_date": {"u.birth_date", kindTime},
+			"created_at": {"u.created_at", kindTime},
+		},
+	}
+}
+
+func (u UserRepository) Update(ctx context.Context, user *domain.User) error {
+	query := `UPDATE users SET name = $1, email = $2, role = $3, birth_date = $4 WHERE id = $5`
+	result, err := conn(ctx, u.db).ExecContext(ctx, query, user.Name, user.Email, user.Role, user.BirthDate, user.ID)
+	if err != nil {
+		return dbError(err, nil)
+	}
+	return affected(result, &domain.ErrUserNotFound{UserID: user.ID})
 }
 
 func (u *UserRepository) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM users WHERE id = $1`
-	_, err := u.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, u.db).ExecContext(ctx, query, id)
 	if err != nil {
-		return err
+		return dbError(err, nil)
 	}
-	return nil
+	return affected(result, &domain.ErrUserNotFound{UserID: id})
 }
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"library/internal/auth"
+	"library/internal/domain"
+	"library/internal/repository"
+	"slices"
+	"time"
+)
+
+type APIKeyer interface {
+	CreateKey(ctx context.Context, name string, scopes []string, createdBy int) (*domain.APIKey, string, error)
+	ListKeys(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.APIKey], error)
+	RevokeKey(ctx context.Context, id int) error
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, error)
+}
+
+type APIKeyUseCase struct {
+	keyRepo repository.APIKeyer
+}
+
+func NewAPIKeyUseCase(keyRepo repository.APIKeyer) APIKeyer {
+	return &APIKeyUseCase{
+		keyRepo: keyRepo,
+	}
+}
+
+// CreateKey сохраняет хеш нового ключа; сам ключ возвращается только один раз
+func (uc *APIKeyUseCase) CreateKey(ctx context.Context, name string, scopes []string, createdBy int) (*domain.APIKey, string, error) {
+	if len(scopes) == 0 {
+		return nil, "", &domain.ErrInvalidScope{}
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(domain.APIKeyScopes, scope) {
+			return nil, "", &domain.ErrInvalidScope{Scope: scope}
+		}
+	}
+
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &domain.APIKey{
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: hash,
+		Scopes:  slices.Compact(slices.Sorted(slices.Values(scopes))),
+	}
+	if createdBy != 0 {
+		key.CreatedBy = &createdBy
+	}
+	if err := uc.keyRepo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return key, plain, nil
+}
+
+func (uc *APIKeyUseCase) ListKeys(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.APIKey], error) {
+	return uc.keyRepo.List(ctx, req)
+}
+
+func (uc *APIKeyUseCase) RevokeKey(ctx context.Context, id int) error {
+	return uc.keyRepo.Revoke(ctx, id)
+}
+
+// Authenticate находит действующий ключ и отмечает время его использования
+func (uc *APIKeyUseCase) Authenticate(ctx context.Context, plain string) (*domain.APIKey, error) {
+	prefix, ok := auth.APIKeyPrefix(plain)
+	if !ok {
+		return nil, auth.ErrInvalidAPIKey
+	}
+
+	key, err := uc.keyRepo.GetByPrefix(ctx, prefix)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil || !auth.CheckAPIKey(key.KeyHash, plain) {
+		return nil, auth.ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if err := uc.keyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+		return nil, err
+	}
+	key.LastUsedAt = &now
+	return key, nil
+}
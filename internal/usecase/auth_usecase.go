@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"library/internal/auth"
+	"library/internal/domain"
+	"library/internal/repository"
+)
+
+type Authenticator interface {
+	Login(ctx context.Context, email, password string) (*auth.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*auth.TokenPair, error)
+}
+
+type AuthUseCase struct {
+	userRepo repository.Userer
+	tokens   *auth.TokenManager
+}
+
+func NewAuthUseCase(userRepo repository.Userer, tokens *auth.TokenManager) Authenticator {
+	return &AuthUseCase{
+		userRepo: userRepo,
+		tokens:   tokens,
+	}
+}
+
+func (uc *AuthUseCase) Login(ctx context.Context, email, password string) (*auth.TokenPair, error) {
+	user, err := uc.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, &domain.ErrInvalidCredentials{}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.PasswordHash == "" || !auth.CheckPassword(user.PasswordHash, password) {
+		return nil, &domain.ErrInvalidCredentials{}
+	}
+	return uc.tokens.Issue(user)
+}
+
+// Refresh перечитывает пользователя, чтобы новый токен отражал его текущую роль
+func (uc *AuthUseCase) Refresh(ctx context.Context, refreshToken string) (*auth.TokenPair, error) {
+	userID, err := uc.tokens.ParseRefresh(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return uc.tokens.Issue(user)
+}
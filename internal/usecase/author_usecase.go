@@ -4,15 +4,18 @@ import (
 	"context"
 	"library/internal/domain"
 	"library/internal/repository"
+	"library/internal/validation"
 )
 
 type Authorer interface {
 	CreateAuthor(ctx context.Context, author *domain.Author) error
 	GetAuthor(ctx context.Context, id int) (*domain.Author, error)
-	ListAuthors(ctx context.Context) ([]*domain.Author, error)
+	ListAuthors(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.Author], error)
 	GetTopAuthors(ctx context.Context, limit int) ([]*domain.AuthorWithRentCount, error)
+	UpdateAuthor(ctx context.Context, author *domain.Author) error
 	DeleteAuthor(ctx context.Context, id int) error
 	GetByBooksAuthor(ctx context.Context, idAuthor int) ([]domain.Book, error)
+	ListAuthorBooks(ctx context.Context, authorID int, req domain.PageRequest) (*domain.Page[domain.Book], error)
 }
 
 type AuthorUseCase struct {
@@ -26,32 +29,32 @@ func NewAuthorUseCase(authorRepo repository.Authorer) Authorer {
 }
 
 func (uc *AuthorUseCase) CreateAuthor(ctx context.Context, author *domain.Author) error {
+	if err := validation.Validate(authorRules(author)...); err != nil {
+		return err
+	}
 	return uc.authorRepo.Create(ctx, author)
 }
 
 func (uc *AuthorUseCase) GetAuthor(ctx context.Context, id int) (*domain.Author, error) {
-	author, err := uc.authorRepo.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-	books, err := uc.GetByBooksAuthor(ctx, id)
-
-	if err != nil {
-		return nil, err
-	}
-
-	author.Books = append(author.Books, books...)
-	return author, nil
+	return uc.authorRepo.GetByID(ctx, id)
 }
 
-func (uc *AuthorUseCase) ListAuthors(ctx context.Context) ([]*domain.Author, error) {
-	return uc.authorRepo.GetAll(ctx)
+func (uc *AuthorUseCase) ListAuthors(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.Author], error) {
+	return uc.authorRepo.List(ctx, req)
 }
 
 func (uc *AuthorUseCase) GetTopAuthors(ctx context.Context, limit int) ([]*domain.AuthorWithRentCount, error) {
 	return uc.authorRepo.GetTopAuthors(ctx, limit)
 }
 
+// UpdateAuthor меняет только данные автора, книги автора не затрагиваются
+func (uc *AuthorUseCase) UpdateAuthor(ctx context.Context, author *domain.Author) error {
+	if err := validation.Validate(authorRules(author)...); err != nil {
+		return err
+	}
+	return uc.authorRepo.Update(ctx, author)
+}
+
 func (uc *AuthorUseCase) DeleteAuthor(ctx context.Context, id int) error {
 	return uc.authorRepo.DeleteAuthor(ctx, id)
 }
@@ -59,3 +62,7 @@ func (uc *AuthorUseCase) DeleteAuthor(ctx context.Context, id int) error {
 func (uc *AuthorUseCase) GetByBooksAuthor(ctx context.Context, idAuthor int) ([]domain.Book, error) {
 	return uc.authorRepo.GetByBooksAuthor(ctx, idAuthor)
 }
+
+func (uc *AuthorUseCase) ListAuthorBooks(ctx context.Context, authorID int, req domain.PageRequest) (*domain.Page[domain.Book], error) {
+	return uc.authorRepo.ListBooks(ctx, authorID, req)
+}
@@ -2,27 +2,43 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"library/internal/domain"
+	"library/internal/isbn"
 	"library/internal/repository"
+	"library/internal/validation"
+	"strings"
 )
 
 type Booker interface {
 	AddBook(ctx context.Context, book *domain.Book) error
 	GetBook(ctx context.Context, id int) (*domain.Book, error)
+	GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error)
+	ListBooks(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.Book], error)
+	GetBookForUpdate(ctx context.Context, id int) (*domain.Book, error)
 	UpdateBook(ctx context.Context, book *domain.Book) error
 	DeleteBook(ctx context.Context, id int) error
 }
 type BookUseCase struct {
-	bookRepo repository.Booker
+	bookRepo   repository.Booker
+	authorRepo repository.Authorer
 }
 
-func NewBookUseCase(bookRepo repository.Booker) Booker {
+func NewBookUseCase(bookRepo repository.Booker, authorRepo repository.Authorer) Booker {
 	return &BookUseCase{
-		bookRepo: bookRepo,
+		bookRepo:   bookRepo,
+		authorRepo: authorRepo,
 	}
 }
 
 func (uc *BookUseCase) AddBook(ctx context.Context, book *domain.Book) error {
+	if err := uc.validate(ctx, book); err != nil {
+		return err
+	}
+	if err := uc.checkISBN(ctx, book); err != nil {
+		return err
+	}
 	return uc.bookRepo.Create(ctx, book)
 }
 
@@ -30,10 +46,73 @@ func (uc *BookUseCase) GetBook(ctx context.Context, id int) (*domain.Book, error
 	return uc.bookRepo.GetByID(ctx, id)
 }
 
+// GetBookByISBN ищет книгу по ISBN-10 или ISBN-13 в любой записи
+func (uc *BookUseCase) GetBookByISBN(ctx context.Context, raw string) (*domain.Book, error) {
+	if err := validation.Validate(validation.Value("isbn", raw, validation.ISBN)); err != nil {
+		return nil, err
+	}
+	normalized, _ := isbn.Normalize(raw)
+	return uc.bookRepo.GetByISBN(ctx, normalized)
+}
+
+func (uc *BookUseCase) ListBooks(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.Book], error) {
+	return uc.bookRepo.List(ctx, req)
+}
+
+func (uc *BookUseCase) GetBookForUpdate(ctx context.Context, id int) (*domain.Book, error) {
+	return uc.bookRepo.GetByIDForUpdate(ctx, id)
+}
+
 func (uc *BookUseCase) UpdateBook(ctx context.Context, book *domain.Book) error {
+	if err := uc.validate(ctx, book); err != nil {
+		return err
+	}
+	if err := uc.checkISBN(ctx, book); err != nil {
+		return err
+	}
 	return uc.bookRepo.Update(ctx, book)
 }
 
 func (uc *BookUseCase) DeleteBook(ctx context.Context, id int) error {
 	return uc.bookRepo.Delete(ctx, id)
 }
+
+// validate проверяет поля книги и существование её автора; корректный ISBN приводится к ISBN-13
+func (uc *BookUseCase) validate(ctx context.Context, book *domain.Book) error {
+	if book.ISBN != nil && strings.TrimSpace(*book.ISBN) == "" {
+		book.ISBN = nil
+	}
+	errs := validation.Collect(bookRules(book)...)
+	if !errs.Has("isbn") && book.ISBN != nil {
+		normalized, _ := isbn.Normalize(*book.ISBN)
+		book.ISBN = &normalized
+	}
+	if !errs.Has("author_id") {
+		exists, err := uc.authorRepo.Exists(ctx, book.AuthorID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			errs.Add("author_id", fmt.Sprintf("author with ID %d does not exist", book.AuthorID))
+		}
+	}
+	return errs.Err()
+}
+
+// checkISBN не даёт завести две книги с одним ISBN
+func (uc *BookUseCase) checkISBN(ctx context.Context, book *domain.Book) error {
+	if book.ISBN == nil {
+		return nil
+	}
+	existing, err := uc.bookRepo.GetByISBN(ctx, *book.ISBN)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != book.ID {
+		return &domain.ErrDuplicateISBN{ISBN: *book.ISBN, BookID: existing.ID}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"library/internal/domain"
+	"library/internal/repository"
+	"library/internal/validation"
+	"strings"
+	"time"
+)
+
+type Copier interface {
+	AddCopy(ctx context.Context, c *domain.Copy) error
+	GetCopy(ctx context.Context, id int) (*domain.Copy, error)
+	GetCopyByBarcode(ctx context.Context, barcode string) (*domain.Copy, error)
+	ListCopies(ctx context.Context, bookID int) ([]domain.Copy, error)
+	FirstAvailable(ctx context.Context, bookID int) (*domain.Copy, error)
+	SetAvailable(ctx context.Context, id int, available bool) error
+	RetireCopy(ctx context.Context, id int) error
+}
+
+type CopyUseCase struct {
+	copyRepo repository.Copier
+}
+
+func NewCopyUseCase(copyRepo repository.Copier) Copier {
+	return &CopyUseCase{
+		copyRepo: copyRepo,
+	}
+}
+
+func (uc *CopyUseCase) AddCopy(ctx context.Context, c *domain.Copy) error {
+	c.Barcode = strings.TrimSpace(c.Barcode)
+	if err := validation.Validate(copyRules(c)...); err != nil {
+		return err
+	}
+	return uc.copyRepo.Create(ctx, c)
+}
+
+func (uc *CopyUseCase) GetCopy(ctx context.Context, id int) (*domain.Copy, error) {
+	return uc.copyRepo.GetByID(ctx, id)
+}
+
+func (uc *CopyUseCase) GetCopyByBarcode(ctx context.Context, barcode string) (*domain.Copy, error) {
+	return uc.copyRepo.GetByBarcode(ctx, strings.TrimSpace(barcode))
+}
+
+func (uc *CopyUseCase) ListCopies(ctx context.Context, bookID int) ([]domain.Copy, error) {
+	return uc.copyRepo.ListByBook(ctx, bookID)
+}
+
+func (uc *CopyUseCase) FirstAvailable(ctx context.Context, bookID int) (*domain.Copy, error) {
+	return uc.copyRepo.FirstAvailable(ctx, bookID)
+}
+
+func (uc *CopyUseCase) SetAvailable(ctx context.Context, id int, available bool) error {
+	return uc.copyRepo.SetAvailable(ctx, id, available)
+}
+
+func (uc *CopyUseCase) RetireCopy(ctx context.Context, id int) error {
+	return uc.copyRepo.Retire(ctx, id, time.Now())
+}
@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"library/internal/domain"
+	"library/internal/repository"
+	"library/internal/validation"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Exporter interface {
+	Export(ctx context.Context, dataset, format string, w io.Writer) error
+}
+
+type ExportUseCase struct {
+	exportRepo repository.Exporter
+}
+
+func NewExportUseCase(exportRepo repository.Exporter) Exporter {
+	return &ExportUseCase{exportRepo: exportRepo}
+}
+
+// exportColumn - колонка выгрузки; значение nil пишется пустой ячейкой CSV и null в JSON Lines
+type exportColumn[T any] struct {
+	name  string
+	value func(T) interface{}
+}
+
+// Export пишет набор данных в w построчно. Неверный набор или формат возвращается до записи в w;
+// до первой строки в w попадает не больше буфера, поэтому и ошибка запроса обычно успевает вернуться до ответа
+func (uc *ExportUseCase) Export(ctx context.Context, dataset, format string, w io.Writer) error {
+	err := validation.Validate(
+		validation.Value("dataset", dataset, validation.OneOf(domain.DatasetBooks, domain.DatasetAuthors, domain.DatasetUsers, domain.DatasetRentals)),
+		validation.Value("format", format, validation.OneOf(domain.FormatCSV, domain.FormatJSONL, domain.FormatExcel)),
+	)
+	if err != nil {
+		return err
+	}
+
+	out := newRowWriter(format, w)
+	switch dataset {
+	case domain.DatasetBooks:
+		return writeTable(ctx, uc.exportRepo.StreamBooks, bookExportColumns, out)
+	case domain.DatasetAuthors:
+		return writeTable(ctx, uc.exportRepo.StreamAuthors, authorExportColumns, out)
+	case domain.DatasetUsers:
+		return writeTable(ctx, uc.exportRepo.StreamUsers, userExportColumns, out)
+	default:
+		return writeTable(ctx, uc.exportRepo.StreamRentals, rentalExportColumns, out)
+	}
+}
+
+var bookExportColumns = []exportColumn[domain.Book]{
+	{"id", func(b domain.Book) interface{} { return b.ID }},
+	{"title", func(b domain.Book) interface{} { return b.Title }},
+	{"author_id", func(b domain.Book) interface{} { return b.AuthorID }},
+	{"author_name", func(b domain.Book) interface{} {
+		if b.Author == nil {
+			return nil
+		}
+		return b.Author.Name
+	}},
+	{"genre", func(b domain.Book) interface{} { return b.Genre }},
+	{"isbn", func(b domain.Book) interface{} { return b.ISBN }},
+	{"publication_year", func(b domain.Book) interface{} { return b.PublicationYear }},
+	{"loan_period_days", func(b domain.Book) interface{} { return b.LoanPeriodDays }},
+	{"available", func(b domain.Book) interface{} { return b.Available }},
+	{"created_at", func(b domain.Book) interface{} { return b.CreatedAt }},
+}
+
+var authorExportColumns = []exportColumn[domain.Author]{
+	{"id", func(a domain.Author) interface{} { return a.ID }},
+	{"name", func(a domain.Author) interface{} { return a.Name }},
+	{"biography", func(a domain.Author) interface{} { return a.Biography }},
+	{"created_at", func(a domain.Author) interface{} { return a.CreatedAt }},
+}
+
+// userExportColumns - без хеша пароля; дата рождения пишется без времени
+var userExportColumns = []exportColumn[domain.User]{
+	{"id", func(u domain.User) interface{} { return u.ID }},
+	{"name", func(u domain.User) interface{} { return u.Name }},
+	{"email", func(u domain.User) interface{} { return u.Email }},
+	{"role", func(u domain.User) interface{} { return u.Role }},
+	{"birth_date", func(u domain.User) interface{} {
+		if u.BirthDate == nil {
+			return nil
+		}
+		return u.BirthDate.Format(time.DateOnly)
+	}},
+	{"balance", func(u domain.User) interface{} { return u.Balance }},
+	{"created_at", func(u domain.User) interface{} { return u.CreatedAt }},
+}
+
+var rentalExportColumns = []exportColumn[domain.BookRental]{
+	{"id", func(r domain.BookRental) interface{} { return r.ID }},
+	{"book_id", func(r domain.BookRental) interface{} { return r.BookID }},
+	{"copy_id", func(r domain.BookRental) interface{} { return r.CopyID }},
+	{"user_id", func(r domain.BookRental) interface{} { return r.UserID }},
+	{"rental_date", func(r domain.BookRental) interface{} { return r.RentalDate }},
+	{"due_date", func(r domain.BookRental) interface{} { return r.DueDate }},
+	{"return_date", func(r domain.BookRental) interface{} { return r.ReturnDate }},
+	{"renewals", func(r domain.BookRental) interface{} { return r.Renewals }},
+	{"overdue", func(r domain.BookRental) interface{} { return r.Overdue }},
+	{"created_at", func(r domain.BookRental) interface{} { return r.CreatedAt }},
+}
+
+func writeTable[T any](ctx context.Context, stream func(context.Context, func(T) error) error, columns []exportColumn[T], out rowWriter) error {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	if err := out.Header(names); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	err := stream(ctx, func(v T) error {
+		for i, c := range columns {
+			values[i] = c.value(v)
+		}
+		return out.Row(values)
+	})
+	if err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+type rowWriter interface {
+	Header(names []string) error
+	Row(values []interface{}) error
+	Flush() error
+}
+
+func newRowWriter(format string, w io.Writer) rowWriter {
+	if format == domain.FormatJSONL {
+		return &jsonlWriter{w: bufio.NewWriter(w)}
+	}
+	return &csvWriter{w: csv.NewWriter(w), excel: format == domain.FormatExcel}
+}
+
+// csvWriter - CSV с заголовком. Для Excel файл начинается с BOM, строки делятся CRLF,
+// а ячейки, похожие на формулу, экранируются апострофом
+type csvWriter struct {
+	w      *csv.Writer
+	excel  bool
+	record []string
+}
+
+func (c *csvWriter) Header(names []string) error {
+	c.w.UseCRLF = c.excel
+	if c.excel {
+		names = append([]string{"\ufeff" + names[0]}, names[1:]...)
+	}
+	c.record = make([]string, len(names))
+	return c.w.Write(names)
+}
+
+func (c *csvWriter) Row(values []interface{}) error {
+	for i, v := range values {
+		text := exportText(v)
+		if c.excel && isText(v) && strings.IndexAny(text, "=+-@\t\r") == 0 {
+			text = "'" + text
+		}
+		c.record[i] = text
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlWriter - объект JSON на строку, ключи в порядке колонок
+type jsonlWriter struct {
+	w     *bufio.Writer
+	names [][]byte
+}
+
+func (j *jsonlWriter) Header(names []string) error {
+	j.names = make([][]byte, len(names))
+	for i, name := range names {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		j.names[i] = append(key, ':')
+	}
+	return nil
+}
+
+func (j *jsonlWriter) Row(values []interface{}) error {
+	j.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			j.w.WriteByte(',')
+		}
+		j.w.Write(j.names[i])
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		j.w.Write(data)
+	}
+	_, err := j.w.WriteString("}\n")
+	return err
+}
+
+func (j *jsonlWriter) Flush() error {
+	return j.w.Flush()
+}
+
+// exportText - значение ячейки CSV; время пишется в RFC 3339, nil - пустой строкой
+func exportText(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	case int:
+		return strconv.Itoa(v)
+	case *int:
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// isText - строковая ячейка; числа со знаком минус формулами не считаются
+func isText(v interface{}) bool {
+	switch v.(type) {
+	case string, *string:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"library/internal/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeExporter отдаёт заранее заданные строки вместо курсора базы
+type fakeExporter struct {
+	books   []domain.Book
+	authors []domain.Author
+	users   []domain.User
+	rentals []domain.BookRental
+}
+
+func stream[T any](rows []T, fn func(T) error) error {
+	for _, row := range rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeExporter) StreamBooks(_ context.Context, fn func(domain.Book) error) error {
+	return stream(f.books, fn)
+}
+
+func (f *fakeExporter) StreamAuthors(_ context.Context, fn func(domain.Author) error) error {
+	return stream(f.authors, fn)
+}
+
+func (f *fakeExporter) StreamUsers(_ context.Context, fn func(domain.User) error) error {
+	return stream(f.users, fn)
+}
+
+func (f *fakeExporter) StreamRentals(_ context.Context, fn func(domain.BookRental) error) error {
+	return stream(f.rentals, fn)
+}
+
+var exportTime = time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+func exportFixture() *fakeExporter {
+	isbn := "9780261103207"
+	return &fakeExporter{
+		books: []domain.Book{
+			{ID: 1, Title: "The lord of the rings", AuthorID: 2, Author: &domain.Author{Name: "Tolkien, J. R. R."}, ISBN: &isbn, Available: true, CreatedAt: exportTime},
+			{ID: 2, Title: "=HYPERLINK(\"http://evil\")", AuthorID: 3, Genre: "+cmd", CreatedAt: exportTime},
+		},
+		users: []domain.User{
+			{ID: 1, Name: "-Minus", Email: "@at@example.com", Role: domain.RolePatron, Balance: -500, CreatedAt: exportTime},
+		},
+	}
+}
+
+func export(t *testing.T, dataset, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := NewExportUseCase(exportFixture()).Export(context.Background(), dataset, format, &buf); err != nil {
+		t.Fatalf("Export(%s, %s): %v", dataset, format, err)
+	}
+	return buf.String()
+}
+
+func TestExportExcel(t *testing.T) {
+	out := export(t, domain.DatasetBooks, domain.FormatExcel)
+	if !strings.HasPrefix(out, "\ufeffid,title,") {
+		t.Fatalf("Excel export must start with a BOM and the header, got %q", out[:20])
+	}
+	if strings.Count(out, "\r\n") != 3 || strings.Count(out, "\n") != 3 {
+		t.Fatalf("Excel export must end every row with CRLF:\n%q", out)
+	}
+	if !strings.Contains(out, `"'=HYPERLINK(""http://evil"")"`) || !strings.Contains(out, ",'+cmd,") {
+		t.Fatalf("formula-like cells are not escaped:\n%s", out)
+	}
+
+	users := export(t, domain.DatasetUsers, domain.FormatExcel)
+	want := "1,'-Minus,'@at@example.com,patron,,-500,2026-03-01T12:30:00Z\r\n"
+	if !strings.HasSuffix(users, want) {
+		t.Fatalf("users row = %q, want text escaped and the negative balance untouched: %q", users, want)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	out := export(t, domain.DatasetBooks, domain.FormatCSV)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if strings.HasPrefix(out, "\ufeff") || strings.Contains(out, "\r") {
+		t.Fatalf("plain CSV must have no BOM and LF line endings:\n%q", out)
+	}
+	want := []string{
+		"id,title,author_id,author_name,genre,isbn,publication_year,loan_period_days,available,created_at",
+		"1,The lord of the rings,2,\"Tolkien, J. R. R.\",,9780261103207,,,true,2026-03-01T12:30:00Z",
+		// без автора и ISBN ячейки пустые; в обычном CSV формулы не экранируются
+		"2,\"=HYPERLINK(\"\"http://evil\"\")\",3,,+cmd,,,,false,2026-03-01T12:30:00Z",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("CSV export:\n%s\nwant:\n%s", out, strings.Join(want, "\n"))
+	}
+}
+
+func TestExportJSONL(t *testing.T) {
+	out := export(t, domain.DatasetBooks, domain.FormatJSONL)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("JSON Lines export has %d lines, want 2:\n%s", len(lines), out)
+	}
+
+	// ключи идут в порядке колонок
+	prev := -1
+	for _, c := range bookExportColumns {
+		i := strings.Index(lines[0], `"`+c.name+`":`)
+		if i <= prev {
+			t.Fatalf("key %q is out of column order in %s", c.name, lines[0])
+		}
+		prev = i
+	}
+
+	var row map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"author_name", "isbn", "publication_year", "loan_period_days"} {
+		if v, ok := row[key]; !ok || v != nil {
+			t.Errorf("%s = %v, want null", key, v)
+		}
+	}
+	if row["title"] != `=HYPERLINK("http://evil")` {
+		t.Errorf("title = %v, JSON Lines must not escape formulas", row["title"])
+	}
+}
+
+// failWriter проваливает тест при любой записи
+type failWriter struct{ t *testing.T }
+
+func (w failWriter) Write(p []byte) (int, error) {
+	w.t.Fatalf("unexpected write of %q", p)
+	return 0, nil
+}
+
+func TestExportRejectsBeforeWriting(t *testing.T) {
+	uc := NewExportUseCase(exportFixture())
+	for _, tt := range []struct{ dataset, format string }{
+		{"holds", domain.FormatCSV},
+		{domain.DatasetBooks, "xlsx"},
+		{"", ""},
+	} {
+		err := uc.Export(context.Background(), tt.dataset, tt.format, failWriter{t})
+		if !errors.Is(err, domain.ErrValidation) {
+			t.Errorf("Export(%q, %q) error = %v, want validation error", tt.dataset, tt.format, err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"library/internal/domain"
+	"library/internal/repository"
+	"time"
+)
+
+type Holder interface {
+	PlaceHold(ctx context.Context, bookID, userID int) (*domain.Hold, error)
+	GetHold(ctx context.Context, id int) (*domain.Hold, error)
+	ListBookHolds(ctx context.Context, bookID int) ([]domain.Hold, error)
+	ListUserHolds(ctx context.Context, userID int) ([]domain.Hold, error)
+	GetReadyHold(ctx context.Context, bookID, userID int) (*domain.Hold, error)
+	GetCopyHold(ctx context.Context, copyID int) (*domain.Hold, error)
+	HasWaitingHolds(ctx context.Context, bookID int) (bool, error)
+	PromoteNext(ctx context.Context, bookID, copyID int, pickupWindow time.Duration) (*domain.Hold, error)
+	ListExpired(ctx context.Context, now time.Time) ([]domain.Hold, error)
+	SetStatus(ctx context.Context, hold *domain.Hold, status string) error
+}
+
+type HoldUseCase struct {
+	holdRepo repository.Holder
+}
+
+func NewHoldUseCase(holdRepo repository.Holder) Holder {
+	return &HoldUseCase{
+		holdRepo: holdRepo,
+	}
+}
+
+func (uc *HoldUseCase) PlaceHold(ctx context.Context, bookID, userID int) (*domain.Hold, error) {
+	hold := &domain.Hold{
+		BookID: bookID,
+		UserID: userID,
+		Status: domain.HoldWaiting,
+	}
+	if err := uc.holdRepo.Create(ctx, hold); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (uc *HoldUseCase) GetHold(ctx context.Context, id int) (*domain.Hold, error) {
+	return uc.holdRepo.GetByID(ctx, id)
+}
+
+func (uc *HoldUseCase) ListBookHolds(ctx context.Context, bookID int) ([]domain.Hold, error) {
+	return uc.holdRepo.ListActiveByBook(ctx, bookID)
+}
+
+func (uc *HoldUseCase) ListUserHolds(ctx context.Context, userID int) ([]domain.Hold, error) {
+	return uc.holdRepo.ListActiveByUser(ctx, userID)
+}
+
+func (uc *HoldUseCase) GetReadyHold(ctx context.Context, bookID, userID int) (*domain.Hold, error) {
+	return uc.holdRepo.GetReadyForUser(ctx, bookID, userID)
+}
+
+func (uc *HoldUseCase) GetCopyHold(ctx context.Context, copyID int) (*domain.Hold, error) {
+	return uc.holdRepo.GetReadyByCopy(ctx, copyID)
+}
+
+func (uc *HoldUseCase) HasWaitingHolds(ctx context.Context, bookID int) (bool, error) {
+	return uc.holdRepo.HasWaiting(ctx, bookID)
+}
+
+// PromoteNext откладывает экземпляр copyID для первого читателя в очереди на время pickupWindow
+func (uc *HoldUseCase) PromoteNext(ctx context.Context, bookID, copyID int, pickupWindow time.Duration) (*domain.Hold, error) {
+	hold, err := uc.holdRepo.NextWaiting(ctx, bookID)
+	if err != nil || hold == nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(pickupWindow)
+	hold.Status = domain.HoldReady
+	hold.CopyID = &copyID
+	hold.ReadyAt = &now
+	hold.ExpiresAt = &expiresAt
+	if err := uc.holdRepo.Update(ctx, hold); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (uc *HoldUseCase) ListExpired(ctx context.Context, now time.Time) ([]domain.Hold, error) {
+	return uc.holdRepo.ListExpired(ctx, now)
+}
+
+func (uc *HoldUseCase) SetStatus(ctx context.Context, hold *domain.Hold, status string) error {
+	hold.Status = status
+	return uc.holdRepo.Update(ctx, hold)
+}
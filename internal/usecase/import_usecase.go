@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"library/internal/domain"
+	"library/internal/isbn"
+	"library/internal/repository"
+	"library/internal/validation"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Importer interface {
+	ImportCSV(ctx context.Context, r io.Reader) (*domain.ImportReport, error)
+}
+
+type ImportUseCase struct {
+	importRepo repository.Importer
+	tx         repository.TxManager
+}
+
+func NewImportUseCase(importRepo repository.Importer, tx repository.TxManager) Importer {
+	return &ImportUseCase{
+		importRepo: importRepo,
+		tx:         tx,
+	}
+}
+
+// Колонки CSV импорта; порядок колонок задаёт заголовок, isbn и copies необязательны
+const (
+	importTitle  = "title"
+	importAuthor = "author"
+	importISBN   = "isbn"
+	importCopies = "copies"
+)
+
+// ImportCSV проверяет каждую строку файла и загружает корректные строки одной транзакцией.
+// Ошибки отдельных строк попадают в отчёт; ошибка возвращается, только если файл не удалось прочитать целиком.
+func (uc *ImportUseCase) ImportCSV(ctx context.Context, r io.Reader) (*domain.ImportReport, error) {
+	rows, rejected, err := parseImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ImportReport{}
+	if len(rows) > 0 {
+		err = uc.tx.Do(ctx, func(ctx context.Context) error {
+			report, err = uc.importRepo.ImportBooks(ctx, rows)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return finishReport(report, rejected), nil
+}
+
+// finishReport добавляет к отчёту строки, отклонённые до загрузки, и пересчитывает итоги
+func finishReport(report *domain.ImportReport, rejected []domain.ImportLine) *domain.ImportReport {
+	report.Lines = append(report.Lines, rejected...)
+	sort.Slice(report.Lines, func(i, j int) bool { return report.Lines[i].Line < report.Lines[j].Line })
+	report.Accepted, report.Rejected = 0, 0
+	for _, line := range report.Lines {
+		if line.Status == domain.ImportAccepted {
+			report.Accepted++
+		} else {
+			report.Rejected++
+		}
+	}
+	return report
+}
+
+// parseImportCSV разбирает файл в строки для загрузки и отклонённые строки с причинами
+func parseImportCSV(r io.Reader) ([]domain.ImportRow, []domain.ImportLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, invalidImport("file is empty")
+	}
+	var headerErr *csv.ParseError
+	if errors.As(err, &headerErr) {
+		return nil, nil, invalidImport(err.Error())
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns, err := importColumns(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []domain.ImportRow
+	var rejected []domain.ImportLine
+	isbnLines := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rejected = append(rejected, domain.ImportLine{
+				Line:   parseErr.StartLine,
+				Status: domain.ImportRejected,
+				Errors: []domain.FieldError{{Field: "line", Message: parseErr.Err.Error()}},
+			})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if isBlank(record) {
+			continue
+		}
+
+		row, errs := importRow(line, record, columns)
+		if row.ISBN != nil && !errs.Has("isbn") {
+			if first, ok := isbnLines[*row.ISBN]; ok {
+				errs.Add("isbn", fmt.Sprintf("repeats the ISBN from line %d", first))
+			} else {
+				isbnLines[*row.ISBN] = line
+			}
+		}
+		if len(errs) > 0 {
+			rejected = append(rejected, domain.ImportLine{Line: line, Status: domain.ImportRejected, Errors: errs})
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, rejected, nil
+}
+
+// importColumns находит колонки по заголовку без учёта регистра; BOM от Excel в начале файла пропускается
+func importColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var errs validation.Errors
+	for _, required := range []string{importTitle, importAuthor} {
+		if _, ok := columns[required]; !ok {
+			errs.Add("header", fmt.Sprintf("missing %s column", required))
+		}
+	}
+	return columns, errs.Err()
+}
+
+func importRow(line int, record []string, columns map[string]int) (domain.ImportRow, validation.Errors) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := domain.ImportRow{
+		Line:       line,
+		Title:      field(importTitle),
+		AuthorName: field(importAuthor),
+		Copies:     1,
+	}
+	errs := validation.Collect(
+		validation.Value("title", row.Title, validation.Required, validation.MaxLength(255)),
+		validation.Value("author", row.AuthorName, validation.Required, validation.MaxLength(255)),
+	)
+
+	if v := field(importISBN); v != "" {
+		normalized, err := isbn.Normalize(v)
+		if err != nil {
+			errs.Add("isbn", err.Error())
+		} else {
+			row.ISBN = &normalized
+		}
+	}
+
+	if v := field(importCopies); v != "" {
+		copies, err := strconv.Atoi(v)
+		if err != nil {
+			errs.Add("copies", "must be an integer")
+		} else {
+			row.Copies = copies
+			errs.Merge(validation.Collect(validation.Value("copies", copies, validation.Between(0, 1000))))
+		}
+	}
+
+	return row, errs
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func invalidImport(message string) error {
+	return &domain.ErrInvalidFields{Fields: []domain.FieldError{{Field: "file", Message: message}}}
+}
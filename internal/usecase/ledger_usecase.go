@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"library/internal/domain"
+	"library/internal/repository"
+)
+
+type Ledgerer interface {
+	ChargeFine(ctx context.Context, userID, rentalID int, amount int64, note string) error
+	Pay(ctx context.Context, userID int, amount int64, note string) (*domain.LedgerEntry, error)
+	Waive(ctx context.Context, userID int, amount int64, note string) (*domain.LedgerEntry, error)
+	GetBalance(ctx context.Context, userID int) (int64, error)
+	ListEntries(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.LedgerEntry], error)
+}
+
+type LedgerUseCase struct {
+	ledgerRepo repository.Ledgerer
+	tx         repository.TxManager
+}
+
+func NewLedgerUseCase(ledgerRepo repository.Ledgerer, tx repository.TxManager) Ledgerer {
+	return &LedgerUseCase{
+		ledgerRepo: ledgerRepo,
+		tx:         tx,
+	}
+}
+
+func (uc *LedgerUseCase) ChargeFine(ctx context.Context, userID, rentalID int, amount int64, note string) error {
+	if amount <= 0 {
+		return &domain.ErrInvalidAmount{Amount: amount, Reason: "must be positive"}
+	}
+	return uc.ledgerRepo.AddEntry(ctx, &domain.LedgerEntry{
+		UserID:   userID,
+		RentalID: &rentalID,
+		Kind:     domain.LedgerFine,
+		Amount:   amount,
+		Note:     note,
+	})
+}
+
+func (uc *LedgerUseCase) Pay(ctx context.Context, userID int, amount int64, note string) (*domain.LedgerEntry, error) {
+	return uc.settle(ctx, userID, domain.LedgerPayment, amount, note)
+}
+
+func (uc *LedgerUseCase) Waive(ctx context.Context, userID int, amount int64, note string) (*domain.LedgerEntry, error) {
+	return uc.settle(ctx, userID, domain.LedgerWaiver, amount, note)
+}
+
+func (uc *LedgerUseCase) GetBalance(ctx context.Context, userID int) (int64, error) {
+	return uc.ledgerRepo.GetBalance(ctx, userID)
+}
+
+func (uc *LedgerUseCase) ListEntries(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.LedgerEntry], error) {
+	return uc.ledgerRepo.ListByUser(ctx, userID, req)
+}
+
+// settle гасит задолженность оплатой или списанием, не допуская переплаты
+func (uc *LedgerUseCase) settle(ctx context.Context, userID int, kind string, amount int64, note string) (*domain.LedgerEntry, error) {
+	if amount <= 0 {
+		return nil, &domain.ErrInvalidAmount{Amount: amount, Reason: "must be positive"}
+	}
+
+	entry := &domain.LedgerEntry{
+		UserID: userID,
+		Kind:   kind,
+		Amount: amount,
+		Note:   note,
+	}
+	err := uc.tx.Do(ctx, func(ctx context.Context) error {
+		balance, err := uc.ledgerRepo.GetBalance(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if amount > balance {
+			return &domain.ErrInvalidAmount{Amount: amount, Reason: "exceeds outstanding balance"}
+		}
+		return uc.ledgerRepo.AddEntry(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,363 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"library/internal/domain"
+	"library/internal/isbn"
+	"library/internal/marc"
+	"library/internal/repository"
+	"library/internal/validation"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type MARCer interface {
+	ExportBook(ctx context.Context, id int, format marc.Format, w io.Writer) error
+	ExportBooks(ctx context.Context, req domain.PageRequest, format marc.Format, w io.Writer) error
+	ImportRecords(ctx context.Context, format marc.Format, r io.Reader) (*domain.ImportReport, error)
+}
+
+// MARCUseCase - обмен каталогом с другими библиотечными системами в MARC21.
+// Книга описывается полями 001 (ID), 008, 020 (ISBN), 100 (автор), 245 (заглавие), 264 (год) и 655 (жанр)
+type MARCUseCase struct {
+	bookRepo   repository.Booker
+	authorRepo repository.Authorer
+	tx         repository.TxManager
+}
+
+func NewMARCUseCase(bookRepo repository.Booker, authorRepo repository.Authorer, tx repository.TxManager) MARCer {
+	return &MARCUseCase{
+		bookRepo:   bookRepo,
+		authorRepo: authorRepo,
+		tx:         tx,
+	}
+}
+
+// marcBook - книга из записи MARC; автор пока известен только по имени
+type marcBook struct {
+	record int
+	book   domain.Book
+}
+
+func (uc *MARCUseCase) ExportBook(ctx context.Context, id int, format marc.Format, w io.Writer) error {
+	book, err := uc.bookRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	writer := format.NewWriter(w)
+	if err := writer.Write(bookRecord(book)); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// ExportBooks выгружает книги по страницам каталога, не держа весь каталог в памяти.
+// Ошибка первой страницы возвращается до того, как в w что-либо записано
+func (uc *MARCUseCase) ExportBooks(ctx context.Context, req domain.PageRequest, format marc.Format, w io.Writer) error {
+	req.Limit = domain.MaxPageLimit
+	page, err := uc.bookRepo.List(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	writer := format.NewWriter(w)
+	for {
+		for i := range page.Items {
+			if err := writer.Write(bookRecord(&page.Items[i])); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		req.After = page.NextCursor
+		if page, err = uc.bookRepo.List(ctx, req); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// ImportRecords заводит книги из файла MARC одной транзакцией. Авторы ищутся по имени или создаются,
+// экземпляры не создаются. В отчёте Line - номер записи в файле, начиная с единицы
+func (uc *MARCUseCase) ImportRecords(ctx context.Context, format marc.Format, r io.Reader) (*domain.ImportReport, error) {
+	books, rejected, err := readMARCBooks(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ImportReport{}
+	if len(books) > 0 {
+		err = uc.tx.Do(ctx, func(ctx context.Context) error {
+			report = &domain.ImportReport{}
+			return uc.importBooks(ctx, books, report)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return finishReport(report, rejected), nil
+}
+
+func (uc *MARCUseCase) importBooks(ctx context.Context, books []marcBook, report *domain.ImportReport) error {
+	authors := make(map[string]int)
+	for _, b := range books {
+		book := b.book
+		if book.ISBN != nil {
+			existing, err := uc.bookRepo.GetByISBN(ctx, *book.ISBN)
+			if err == nil {
+				report.Lines = append(report.Lines, rejectedRecord(b.record, "isbn",
+					fmt.Sprintf("already belongs to book with ID %d", existing.ID)))
+				continue
+			}
+			if !errors.Is(err, domain.ErrNotFound) {
+				return err
+			}
+		}
+
+		authorID, err := uc.resolveAuthor(ctx, book.Author.Name, authors, report)
+		if err != nil {
+			return err
+		}
+		book.AuthorID = authorID
+		book.Author = nil
+		book.CreatedAt = time.Now()
+		if err := uc.bookRepo.Create(ctx, &book); err != nil {
+			return err
+		}
+		report.Lines = append(report.Lines, domain.ImportLine{Line: b.record, Status: domain.ImportAccepted, BookID: book.ID})
+	}
+	return nil
+}
+
+// resolveAuthor находит автора по имени без учёта регистра или заводит нового
+func (uc *MARCUseCase) resolveAuthor(ctx context.Context, name string, authors map[string]int, report *domain.ImportReport) (int, error) {
+	key := strings.ToLower(name)
+	if id, ok := authors[key]; ok {
+		return id, nil
+	}
+
+	author, err := uc.authorRepo.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrNotFound) {
+		author = &domain.Author{Name: name}
+		if err = uc.authorRepo.Create(ctx, author); err == nil {
+			report.AuthorsCreated++
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	authors[key] = author.ID
+	return author.ID, nil
+}
+
+// readMARCBooks читает все записи до транзакции: тело запроса нельзя перечитать при её повторе
+func readMARCBooks(format marc.Format, r io.Reader) ([]marcBook, []domain.ImportLine, error) {
+	reader := format.NewReader(r)
+	var books []marcBook
+	var rejected []domain.ImportLine
+	isbnRecords := make(map[string]int)
+	for n := 1; ; n++ {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var recErr *marc.RecordError
+		if errors.As(err, &recErr) {
+			rejected = append(rejected, rejectedRecord(n, "record", recErr.Err.Error()))
+			continue
+		}
+		if errors.Is(err, marc.ErrFormat) || errors.Is(err, marc.ErrEncoding) {
+			return nil, nil, invalidImport(err.Error())
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		book, errs := recordBook(rec)
+		if book.ISBN != nil && !errs.Has("isbn") {
+			if first, ok := isbnRecords[*book.ISBN]; ok {
+				errs.Add("isbn", fmt.Sprintf("repeats the ISBN from record %d", first))
+			} else {
+				isbnRecords[*book.ISBN] = n
+			}
+		}
+		if len(errs) > 0 {
+			rejected = append(rejected, domain.ImportLine{Line: n, Status: domain.ImportRejected, Errors: errs})
+			continue
+		}
+		books = append(books, marcBook{record: n, book: book})
+	}
+
+	if len(books) == 0 && len(rejected) == 0 {
+		return nil, nil, invalidImport(fmt.Sprintf("file contains no %s records", format))
+	}
+	return books, rejected, nil
+}
+
+func rejectedRecord(record int, field, message string) domain.ImportLine {
+	return domain.ImportLine{
+		Line:   record,
+		Status: domain.ImportRejected,
+		Errors: []domain.FieldError{{Field: field, Message: message}},
+	}
+}
+
+// bookRecord описывает книгу записью MARC21 без пунктуации ISBD, поэтому обратный импорт не меняет значений.
+// ISBN пишется в обеих формах, если у книги есть ISBN-10
+func bookRecord(book *domain.Book) *marc.Record {
+	rec := &marc.Record{Leader: marc.MonographLeader}
+	rec.AddControl("001", strconv.Itoa(book.ID))
+	rec.AddControl("008", fixedData(book))
+
+	if book.ISBN != nil {
+		rec.AddField("020", ' ', ' ', marc.Subfield{Code: 'a', Value: *book.ISBN})
+		if isbn10, ok := isbn.To10(*book.ISBN); ok {
+			rec.AddField("020", ' ', ' ', marc.Subfield{Code: 'a', Value: isbn10})
+		}
+	}
+	// первый индикатор 245 говорит, есть ли в записи главная точка доступа 1XX
+	titleInd := byte('0')
+	if book.Author != nil {
+		rec.AddField("100", '0', ' ', marc.Subfield{Code: 'a', Value: book.Author.Name})
+		titleInd = '1'
+	}
+	rec.AddField("245", titleInd, '0', marc.Subfield{Code: 'a', Value: book.Title})
+	if book.PublicationYear != nil {
+		rec.AddField("264", ' ', '1', marc.Subfield{Code: 'c', Value: strconv.Itoa(*book.PublicationYear)})
+	}
+	if book.Genre != "" {
+		rec.AddField("655", ' ', '4', marc.Subfield{Code: 'a', Value: book.Genre})
+	}
+	return rec
+}
+
+// fixedData - поле 008: дата заведения записи и год издания, язык и место издания не известны
+func fixedData(book *domain.Book) string {
+	dateType, date1 := "n", "uuuu"
+	if book.PublicationYear != nil && *book.PublicationYear >= 1 && *book.PublicationYear <= 9999 {
+		dateType, date1 = "s", fmt.Sprintf("%04d", *book.PublicationYear)
+	}
+	return book.CreatedAt.Format("060102") + dateType + date1 + "    " + "xx " + strings.Repeat(" ", 17) + "und" + " d"
+}
+
+// recordBook переводит запись в книгу. Заглавие - 245 $a и $b, автор - 100 $a (или 110/111 для организаций),
+// ISBN - первый корректный 020 $a, год - 264 $c, 260 $c или 008, жанр - 655 $a
+func recordBook(rec *marc.Record) (domain.Book, validation.Errors) {
+	trim := trimISBD
+	if !rec.Punctuated() {
+		trim = strings.TrimSpace
+	}
+	book := domain.Book{
+		Title:  trim(rec.Value("245", 'a')),
+		Author: &domain.Author{},
+		Genre:  trim(rec.Value("655", 'a')),
+	}
+	if subtitle := trim(rec.Value("245", 'b')); subtitle != "" {
+		book.Title += ": " + subtitle
+	}
+	for _, tag := range []string{"100", "110", "111"} {
+		if name := trim(rec.Value(tag, 'a')); name != "" {
+			book.Author.Name = name
+			break
+		}
+	}
+	book.PublicationYear = recordYear(rec)
+
+	errs := validation.Collect(
+		validation.Value("title", book.Title, validation.Required, validation.MaxLength(255)),
+		validation.Value("author", book.Author.Name, validation.Required, validation.MaxLength(255)),
+		validation.Value("genre", book.Genre, validation.MaxLength(100)),
+		validation.Optional("publication_year", book.PublicationYear, validation.Between(1, 9999)),
+	)
+
+	// в 020 $a после номера часто идёт уточнение вида «(pbk.)»; отменённые номера лежат в $z и не читаются
+	var isbnErr error
+	for _, v := range rec.Values("020", 'a') {
+		fields := strings.Fields(v)
+		if len(fields) == 0 {
+			continue
+		}
+		normalized, err := isbn.Normalize(fields[0])
+		if err == nil {
+			book.ISBN = &normalized
+			isbnErr = nil
+			break
+		}
+		if isbnErr == nil {
+			isbnErr = err
+		}
+	}
+	if isbnErr != nil {
+		errs.Add("isbn", isbnErr.Error())
+	}
+	return book, errs
+}
+
+func recordYear(rec *marc.Record) *int {
+	for _, tag := range []string{"264", "260"} {
+		if year, ok := firstYear(rec.Value(tag, 'c')); ok {
+			return &year
+		}
+	}
+	if fixed := rec.Control("008"); len(fixed) >= 11 {
+		if year, ok := firstYear(fixed[7:11]); ok {
+			return &year
+		}
+	}
+	return nil
+}
+
+// firstYear - первые четыре цифры подряд, как в «c2003.» или «[1999]»
+func firstYear(s string) (int, bool) {
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			digits = 0
+			continue
+		}
+		digits++
+		if digits == 4 {
+			year, _ := strconv.Atoi(s[i-3 : i+1])
+			return year, year > 0
+		}
+	}
+	return 0, false
+}
+
+// isbdTerminators - знаки, которыми ISBD отделяет следующее подполе; кроме запятой, перед ними стоит пробел
+var isbdTerminators = []string{" /", " :", " ;", " =", ","}
+
+// abbreviations - сокращения, точка после которых принадлежит значению, а не пунктуации
+var abbreviations = map[string]bool{
+	"jr": true, "sr": true, "st": true, "dr": true, "inc": true, "co": true, "ltd": true, "corp": true, "ed": true, "etc": true,
+}
+
+// trimISBD снимает с конца значения один знак пунктуации ISBD и завершающую точку.
+// Точка после инициала («Tolkien, J. R. R.») или сокращения («Jr.») остаётся
+func trimISBD(s string) string {
+	s = strings.TrimSpace(s)
+	for _, t := range isbdTerminators {
+		if strings.HasSuffix(s, t) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, t))
+			break
+		}
+	}
+	if strings.HasSuffix(s, ".") && !abbreviated(s) {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "."))
+	}
+	return s
+}
+
+// abbreviated - заканчивается ли значение инициалом или сокращением с точкой
+func abbreviated(s string) bool {
+	body := strings.TrimSuffix(s, ".")
+	word := body[strings.LastIndexAny(body, " ,")+1:]
+	return utf8.RuneCountInString(word) == 1 || strings.Contains(word, ".") || abbreviations[strings.ToLower(word)]
+}
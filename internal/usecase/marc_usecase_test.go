@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"bytes"
+	"library/internal/domain"
+	"library/internal/marc"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTrimISBD(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Arithmetic /", "Arithmetic"},
+		{"Война и мир :", "Война и мир"},
+		{"Sandburg, Carl,", "Sandburg, Carl"},
+		{"The lord of the rings.", "The lord of the rings"},
+		{"Tolkien, J. R. R.", "Tolkien, J. R. R."},
+		{"Tolkien, J.R.R.", "Tolkien, J.R.R."},
+		{"King, Martin Luther, Jr.", "King, Martin Luther, Jr."},
+		{"Historical fiction.", "Historical fiction"},
+		{"Title = Parallel title =", "Title = Parallel title"},
+		{"  Plain  ", "Plain"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := trimISBD(tt.in); got != tt.want {
+			t.Errorf("trimISBD(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBookRecordRoundTrip(t *testing.T) {
+	isbn13, year, loan := "9780261103207", 2005, 21
+	books := []domain.Book{
+		{ID: 1, Title: "The lord of the rings.", Author: &domain.Author{Name: "Tolkien, J. R. R."}, Genre: "Fantasy", ISBN: &isbn13, PublicationYear: &year},
+		{ID: 2, Title: "Война и мир: роман-эпопея", Author: &domain.Author{Name: "Лев Толстой"}, LoanPeriodDays: &loan},
+	}
+	for _, format := range []marc.Format{marc.MARC21, marc.MARCXML} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := format.NewWriter(&buf)
+			for i := range books {
+				books[i].CreatedAt = time.Now()
+				if err := w.Write(bookRecord(&books[i])); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			imported, rejected, err := readMARCBooks(format, &buf)
+			if err != nil || len(rejected) > 0 {
+				t.Fatalf("readMARCBooks() rejected %v, error %v", rejected, err)
+			}
+			for i, b := range imported {
+				want, got := books[i], b.book
+				if got.Title != want.Title || got.Author.Name != want.Author.Name || got.Genre != want.Genre {
+					t.Errorf("book %d: got %q by %q (%q), want %q by %q (%q)",
+						i, got.Title, got.Author.Name, got.Genre, want.Title, want.Author.Name, want.Genre)
+				}
+				if !equalPtr(got.ISBN, want.ISBN) || !equalPtr(got.PublicationYear, want.PublicationYear) {
+					t.Errorf("book %d: isbn %v year %v, want %v %v", i, got.ISBN, got.PublicationYear, want.ISBN, want.PublicationYear)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordBookFixture(t *testing.T) {
+	file, err := os.Open("../marc/testdata/records.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	books, rejected, err := readMARCBooks(marc.MARCXML, file)
+	if err != nil || len(rejected) > 0 || len(books) != 3 {
+		t.Fatalf("readMARCBooks() = %d books, rejected %v, error %v", len(books), rejected, err)
+	}
+
+	tests := []struct {
+		title, author, isbn, genre string
+		year                       int
+	}{
+		{"Arithmetic", "Sandburg, Carl", "9780152038656", "", 1993},
+		{"Война и мир: роман-эпопея", "Толстой, Лев Николаевич", "9785170803545", "Historical fiction", 2012},
+		{"The lord of the rings", "Tolkien, J. R. R.", "9780261103207", "", 2005},
+	}
+	for i, tt := range tests {
+		b := books[i].book
+		if b.Title != tt.title || b.Author.Name != tt.author || b.Genre != tt.genre {
+			t.Errorf("record %d: got %q by %q (%q)", i+1, b.Title, b.Author.Name, b.Genre)
+		}
+		if b.ISBN == nil || *b.ISBN != tt.isbn {
+			t.Errorf("record %d: isbn %v, want %s", i+1, b.ISBN, tt.isbn)
+		}
+		if b.PublicationYear == nil || *b.PublicationYear != tt.year {
+			t.Errorf("record %d: year %v, want %d", i+1, b.PublicationYear, tt.year)
+		}
+	}
+}
+
+func TestReadMARCBooksRejects(t *testing.T) {
+	doc := `<collection>
+		<record><datafield tag="245" ind1="0" ind2="0"><subfield code="a">No author</subfield></datafield></record>
+		<record><datafield tag="100" ind1="0" ind2=" "><subfield code="a">A</subfield></datafield>
+			<datafield tag="245" ind1="1" ind2="0"><subfield code="a">Bad ISBN</subfield></datafield>
+			<datafield tag="020" ind1=" " ind2=" "><subfield code="a">9780261103208</subfield></datafield></record>
+		<record><datafield tag="100" ind1="0" ind2=" "><subfield code="a">A</subfield></datafield>
+			<datafield tag="245" ind1="1" ind2="0"><subfield code="a">First</subfield></datafield>
+			<datafield tag="020" ind1=" " ind2=" "><subfield code="a">0261103202</subfield></datafield></record>
+		<record><datafield tag="100" ind1="0" ind2=" "><subfield code="a">A</subfield></datafield>
+			<datafield tag="245" ind1="1" ind2="0"><subfield code="a">Same ISBN</subfield></datafield>
+			<datafield tag="020" ind1=" " ind2=" "><subfield code="a">978-0-261-10320-7</subfield></datafield></record>
+		<record><datafield tag="245" ind1="10" ind2="0"/></record>
+	</collection>`
+
+	books, rejected, err := readMARCBooks(marc.MARCXML, bytes.NewBufferString(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 1 || books[0].record != 3 {
+		t.Fatalf("accepted %+v, want only record 3", books)
+	}
+	want := map[int]string{1: "author", 2: "isbn", 4: "isbn", 5: "record"}
+	if len(rejected) != len(want) {
+		t.Fatalf("rejected %+v", rejected)
+	}
+	for _, line := range rejected {
+		if field := want[line.Line]; len(line.Errors) == 0 || line.Errors[0].Field != field {
+			t.Errorf("record %d rejected with %+v, want field %q", line.Line, line.Errors, field)
+		}
+	}
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -2,12 +2,23 @@ package usecase
 
 import (
 	"context"
+	"library/internal/domain"
 	"library/internal/repository"
+	"time"
 )
 
 type Rentaler interface {
-	RentBook(ctx context.Context, bookID, userID int) error
-	ReturnBook(ctx context.Context, bookID int) error
+	RentBook(ctx context.Context, bookID, copyID, userID int, dueDate time.Time) error
+	ReturnCopy(ctx context.Context, copyID int) (*domain.BookRental, error)
+	GetCopyRental(ctx context.Context, copyID int) (*domain.BookRental, error)
+	GetUserRental(ctx context.Context, bookID, userID int) (*domain.BookRental, error)
+	GetRental(ctx context.Context, id int) (*domain.BookRental, error)
+	ListActiveBooks(ctx context.Context, userID int) ([]domain.Book, error)
+	ListLoans(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.Loan], error)
+	ListLoanHistory(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.Loan], error)
+	RenewRental(ctx context.Context, id int, dueDate time.Time) (*domain.BookRental, error)
+	ListOverdue(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error)
+	ListOverdueByUser(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error)
 }
 
 type RentalUseCase struct {
@@ -20,10 +31,46 @@ func NewRentUseCase(rentRepo repository.Rentaler) Rentaler {
 	}
 }
 
-func (uc *RentalUseCase) RentBook(ctx context.Context, bookID, userID int) error {
-	return uc.rentalRepo.RentBook(ctx, bookID, userID)
+func (uc *RentalUseCase) RentBook(ctx context.Context, bookID, copyID, userID int, dueDate time.Time) error {
+	return uc.rentalRepo.RentBook(ctx, bookID, copyID, userID, dueDate)
 }
 
-func (uc *RentalUseCase) ReturnBook(ctx context.Context, bookID int) error {
-	return uc.rentalRepo.ReturnBook(ctx, bookID)
+func (uc *RentalUseCase) ReturnCopy(ctx context.Context, copyID int) (*domain.BookRental, error) {
+	return uc.rentalRepo.ReturnCopy(ctx, copyID)
+}
+
+func (uc *RentalUseCase) GetCopyRental(ctx context.Context, copyID int) (*domain.BookRental, error) {
+	return uc.rentalRepo.GetActiveByCopy(ctx, copyID)
+}
+
+func (uc *RentalUseCase) GetUserRental(ctx context.Context, bookID, userID int) (*domain.BookRental, error) {
+	return uc.rentalRepo.GetActiveByBookAndUser(ctx, bookID, userID)
+}
+
+func (uc *RentalUseCase) GetRental(ctx context.Context, id int) (*domain.BookRental, error) {
+	return uc.rentalRepo.GetByID(ctx, id)
+}
+
+func (uc *RentalUseCase) ListActiveBooks(ctx context.Context, userID int) ([]domain.Book, error) {
+	return uc.rentalRepo.ListActiveBooksByUser(ctx, userID)
+}
+
+func (uc *RentalUseCase) ListLoans(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.Loan], error) {
+	return uc.rentalRepo.ListLoansByUser(ctx, userID, true, req)
+}
+
+func (uc *RentalUseCase) ListLoanHistory(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.Loan], error) {
+	return uc.rentalRepo.ListLoansByUser(ctx, userID, false, req)
+}
+
+func (uc *RentalUseCase) RenewRental(ctx context.Context, id int, dueDate time.Time) (*domain.BookRental, error) {
+	return uc.rentalRepo.Renew(ctx, id, dueDate)
+}
+
+func (uc *RentalUseCase) ListOverdue(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error) {
+	return uc.rentalRepo.ListOverdue(ctx, req)
+}
+
+func (uc *RentalUseCase) ListOverdueByUser(ctx context.Context, userID int, req domain.PageRequest) (*domain.Page[domain.OverdueRental], error) {
+	return uc.rentalRepo.ListOverdueByUser(ctx, userID, req)
 }
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"fmt"
+	"library/internal/domain"
+	"library/internal/validation"
+)
+
+// Правила проверки входных данных; ограничения длины совпадают с размерами колонок в базе
+
+func authorRules(author *domain.Author) []validation.Check {
+	checks := []validation.Check{
+		validation.Value("name", author.Name, validation.Required, validation.MaxLength(255)),
+		validation.Value("biography", author.Biography, validation.MaxLength(10000)),
+	}
+	for i := range author.Books {
+		prefix := fmt.Sprintf("books[%d].", i)
+		checks = append(checks,
+			validation.Value(prefix+"title", author.Books[i].Title, validation.Required, validation.MaxLength(255)))
+	}
+	return checks
+}
+
+func bookRules(book *domain.Book) []validation.Check {
+	return []validation.Check{
+		validation.Value("title", book.Title, validation.Required, validation.MaxLength(255)),
+		validation.Value("author_id", book.AuthorID, validation.Min(1)),
+		validation.Value("genre", book.Genre, validation.MaxLength(100)),
+		validation.Optional("loan_period_days", book.LoanPeriodDays, validation.Between(1, 365)),
+		validation.Optional("isbn", book.ISBN, validation.ISBN),
+		validation.Optional("publication_year", book.PublicationYear, validation.Between(1, 9999)),
+	}
+}
+
+func copyRules(c *domain.Copy) []validation.Check {
+	return []validation.Check{
+		validation.Value("barcode", c.Barcode, validation.Required, validation.MaxLength(32), notGeneratedBarcode),
+	}
+}
+
+var notGeneratedBarcode validation.Rule[string] = func(v string) string {
+	if domain.IsGeneratedBarcode(v) {
+		return "must not have the form B000000000, it is reserved for generated barcodes"
+	}
+	return ""
+}
+
+func userRules(user *domain.User) []validation.Check {
+	checks := []validation.Check{
+		validation.Value("name", user.Name, validation.Required, validation.MaxLength(255)),
+		validation.Value("email", user.Email, validation.Required, validation.MaxLength(255), validation.Email),
+		validation.Value("role", user.Role, validation.OneOf(domain.RolePatron, domain.RoleLibrarian)),
+		validation.Optional("birth_date", user.BirthDate, validation.NotInFuture),
+	}
+	if user.Password != "" {
+		checks = append(checks, validation.Value("password", user.Password, validation.MinLength(8), validation.MaxLength(72)))
+	}
+	return checks
+}
+
+func searchRules(query *domain.SearchQuery) []validation.Check {
+	return []validation.Check{
+		validation.Value("q", query.Text, validation.Required, validation.MaxLength(200)),
+		validation.Value("lang", query.Language, validation.OneOf(domain.SearchEnglish, domain.SearchRussian)),
+		validation.Value("limit", query.Limit, validation.Between(0, domain.MaxPageLimit)),
+	}
+}
+
+func suggestRules(prefix string, limit int) []validation.Check {
+	return []validation.Check{
+		validation.Value("prefix", prefix, validation.Required, validation.MaxLength(100)),
+		validation.Value("limit", limit, validation.Between(1, 50)),
+	}
+}
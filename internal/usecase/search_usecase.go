@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"library/internal/domain"
+	"library/internal/repository"
+	"library/internal/validation"
+	"strings"
+	"unicode"
+)
+
+type Searcher interface {
+	Search(ctx context.Context, query domain.SearchQuery) ([]domain.SearchHit, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error)
+}
+
+type SearchUseCase struct {
+	searchRepo repository.Searcher
+}
+
+func NewSearchUseCase(searchRepo repository.Searcher) Searcher {
+	return &SearchUseCase{
+		searchRepo: searchRepo,
+	}
+}
+
+// Search ищет книги и авторов; без явного языка запрос на кириллице ищется с русской морфологией
+func (uc *SearchUseCase) Search(ctx context.Context, query domain.SearchQuery) ([]domain.SearchHit, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Language == "" {
+		query.Language = detectLanguage(query.Text)
+	}
+	if err := validation.Validate(searchRules(&query)...); err != nil {
+		return nil, err
+	}
+	return uc.searchRepo.Search(ctx, query)
+}
+
+// Suggest - подсказки по началу названия книги или имени автора, устойчивые к опечаткам
+func (uc *SearchUseCase) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	if limit == 0 {
+		limit = domain.DefaultSuggestLimit
+	}
+	if err := validation.Validate(suggestRules(prefix, limit)...); err != nil {
+		return nil, err
+	}
+	return uc.searchRepo.Suggest(ctx, prefix, limit)
+}
+
+func detectLanguage(text string) string {
+	for _, r := range text {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return domain.SearchRussian
+		}
+	}
+	return domain.SearchEnglish
+}
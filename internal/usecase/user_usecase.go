@@ -2,14 +2,18 @@ package usecase
 
 import (
 	"context"
+	"library/internal/auth"
 	"library/internal/domain"
 	"library/internal/repository"
+	"library/internal/validation"
 )
 
 type Userer interface {
 	CreateUser(ctx context.Context, user *domain.User) error
 	GetByIDUser(ctx context.Context, id int) (*domain.User, error)
-	GetAllUsers(ctx context.Context) ([]*domain.User, error)
+	GetByEmailUser(ctx context.Context, email string) (*domain.User, error)
+	ListUsers(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.User], error)
+	UpdateUser(ctx context.Context, user *domain.User) error
 	DeleteUser(ctx context.Context, id int) error
 }
 
@@ -24,6 +28,22 @@ func NewUserUseCase(userRepo repository.Userer) Userer {
 }
 
 func (u UserUseCase) CreateUser(ctx context.Context, user *domain.User) error {
+	if user.Role == "" {
+		user.Role = domain.RolePatron
+	}
+	if err := validation.Validate(userRules(user)...); err != nil {
+		return err
+	}
+
+	if user.Password != "" {
+		hash, err := auth.HashPassword(user.Password)
+		if err != nil {
+			return err
+		}
+		user.PasswordHash = hash
+		user.Password = ""
+	}
+
 	return u.userRepo.Create(ctx, user)
 }
 
@@ -31,9 +51,23 @@ func (u UserUseCase) GetByIDUser(ctx context.Context, id int) (*domain.User, err
 	return u.userRepo.GetByID(ctx, id)
 }
 
-func (u UserUseCase) GetAllUsers(ctx context.Context) ([]*domain.User, error) {
-	return u.userRepo.GetAllUsers(ctx)
+func (u UserUseCase) GetByEmailUser(ctx context.Context, email string) (*domain.User, error) {
+	return u.userRepo.GetByEmail(ctx, email)
 }
+
+func (u UserUseCase) ListUsers(ctx context.Context, req domain.PageRequest) (*domain.Page[domain.User], error) {
+	return u.userRepo.List(ctx, req)
+}
+
+// UpdateUser меняет профиль пользователя; пароль этим методом не меняется
+func (u UserUseCase) UpdateUser(ctx context.Context, user *domain.User) error {
+	user.Password = ""
+	if err := validation.Validate(userRules(user)...); err != nil {
+		return err
+	}
+	return u.userRepo.Update(ctx, user)
+}
+
 func (u UserUseCase) DeleteUser(ctx context.Context, id int) error {
 	return u.userRepo.Delete(ctx, id)
 }
@@ -0,0 +1,152 @@
+package validation
+
+import (
+	"cmp"
+	"fmt"
+	"library/internal/domain"
+	"library/internal/isbn"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Rule проверяет значение и возвращает текст ошибки; пустая строка означает, что значение корректно
+type Rule[T any] func(v T) string
+
+// Check - проверка одного поля, nil означает что поле корректно
+type Check func() *domain.FieldError
+
+// Value проверяет значение правилами по порядку и сообщает о первом нарушенном
+func Value[T any](field string, v T, rules ...Rule[T]) Check {
+	return func() *domain.FieldError {
+		for _, rule := range rules {
+			if msg := rule(v); msg != "" {
+				return &domain.FieldError{Field: field, Message: msg}
+			}
+		}
+		return nil
+	}
+}
+
+// Optional проверяет значение, только если оно задано
+func Optional[T any](field string, v *T, rules ...Rule[T]) Check {
+	if v == nil {
+		return func() *domain.FieldError { return nil }
+	}
+	return Value(field, *v, rules...)
+}
+
+// Errors - накопленные ошибки полей
+type Errors []domain.FieldError
+
+// Collect выполняет все проверки и собирает ошибки всех полей сразу
+func Collect(checks ...Check) Errors {
+	var errs Errors
+	for _, check := range checks {
+		if fe := check(); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+	return errs
+}
+
+func Validate(checks ...Check) error {
+	return Collect(checks...).Err()
+}
+
+func (e *Errors) Add(field, message string) {
+	*e = append(*e, domain.FieldError{Field: field, Message: message})
+}
+
+func (e *Errors) Merge(other Errors) {
+	*e = append(*e, other...)
+}
+
+func (e Errors) Has(field string) bool {
+	return slices.ContainsFunc(e, func(fe domain.FieldError) bool { return fe.Field == field })
+}
+
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return &domain.ErrInvalidFields{Fields: e}
+}
+
+var Required Rule[string] = func(v string) string {
+	if strings.TrimSpace(v) == "" {
+		return "is required"
+	}
+	return ""
+}
+
+func MaxLength(n int) Rule[string] {
+	return func(v string) string {
+		if utf8.RuneCountInString(v) > n {
+			return fmt.Sprintf("must be at most %d characters", n)
+		}
+		return ""
+	}
+}
+
+func MinLength(n int) Rule[string] {
+	return func(v string) string {
+		if utf8.RuneCountInString(v) < n {
+			return fmt.Sprintf("must be at least %d characters", n)
+		}
+		return ""
+	}
+}
+
+// Email допускает только голый адрес, без имени и угловых скобок
+var Email Rule[string] = func(v string) string {
+	addr, err := mail.ParseAddress(v)
+	if err != nil || addr.Address != v {
+		return "must be a valid email address"
+	}
+	return ""
+}
+
+// ISBN принимает ISBN-10 или ISBN-13 с верной контрольной цифрой, дефисы и пробелы допускаются
+var ISBN Rule[string] = func(v string) string {
+	if _, err := isbn.Normalize(v); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func OneOf[T comparable](allowed ...T) Rule[T] {
+	return func(v T) string {
+		if !slices.Contains(allowed, v) {
+			return fmt.Sprintf("must be one of %v", allowed)
+		}
+		return ""
+	}
+}
+
+func Min[T cmp.Ordered](min T) Rule[T] {
+	return func(v T) string {
+		if v < min {
+			return fmt.Sprintf("must be at least %v", min)
+		}
+		return ""
+	}
+}
+
+func Between[T cmp.Ordered](min, max T) Rule[T] {
+	return func(v T) string {
+		if v < min || v > max {
+			return fmt.Sprintf("must be between %v and %v", min, max)
+		}
+		return ""
+	}
+}
+
+var NotInFuture Rule[time.Time] = func(v time.Time) string {
+	if v.After(time.Now()) {
+		return "must not be in the future"
+	}
+	return ""
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS ux_unique_book_rental_book_id;
+DROP INDEX IF EXISTS ux_book_rental_active_book;
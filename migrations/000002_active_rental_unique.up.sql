@@ -0,0 +1,2 @@
+CREATE UNIQUE INDEX ux_book_rental_active_book ON book_rental(book_id) WHERE return_date IS NULL;
+CREATE UNIQUE INDEX ux_unique_book_rental_book_id ON unique_book_rental(book_id);
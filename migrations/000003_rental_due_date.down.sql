@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_book_rental_due_date;
+ALTER TABLE book_rental DROP COLUMN IF EXISTS due_date;
+ALTER TABLE books DROP COLUMN IF EXISTS loan_period_days;
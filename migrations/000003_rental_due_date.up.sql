@@ -0,0 +1,9 @@
+ALTER TABLE books ADD COLUMN loan_period_days INTEGER CHECK (loan_period_days > 0);
+ALTER TABLE book_rental ADD COLUMN due_date TIMESTAMP WITH TIME ZONE;
+-- Разовое заполнение для выдач, сделанных до появления сроков: миграция не видит настроек приложения,
+-- поэтому берётся значение LOAN_PERIOD_DAYS по умолчанию - 14 дней. Если в библиотеке другой срок,
+-- после миграции пересчитайте открытые выдачи: UPDATE book_rental SET due_date = rental_date + <срок>
+-- WHERE return_date IS NULL. Новые выдачи получают срок из конфигурации или loan_period_days книги.
+UPDATE book_rental SET due_date = rental_date + INTERVAL '14 days';
+ALTER TABLE book_rental ALTER COLUMN due_date SET NOT NULL;
+CREATE INDEX idx_book_rental_due_date ON book_rental(due_date) WHERE return_date IS NULL;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_user_ledger_append_only ON user_ledger;
+DROP FUNCTION IF EXISTS user_ledger_append_only();
+DROP TABLE IF EXISTS user_ledger;
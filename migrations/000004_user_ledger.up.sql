@@ -0,0 +1,24 @@
+CREATE TABLE user_ledger (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rental_id INTEGER REFERENCES book_rental(id) ON DELETE SET NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('fine', 'payment', 'waiver')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_user_ledger_user_id ON user_ledger(user_id);
+
+-- Журнал только дополняется; изменения разрешены лишь каскадным действиям внешних ключей
+CREATE FUNCTION user_ledger_append_only() RETURNS trigger AS $$
+BEGIN
+    IF pg_trigger_depth() > 1 THEN
+        RETURN COALESCE(NEW, OLD);
+    END IF;
+    RAISE EXCEPTION 'user_ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_user_ledger_append_only
+    BEFORE UPDATE OR DELETE ON user_ledger
+    FOR EACH ROW EXECUTE FUNCTION user_ledger_append_only();
@@ -0,0 +1,15 @@
+CREATE TABLE holds (
+    id SERIAL PRIMARY KEY,
+    book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'waiting'
+        CHECK (status IN ('waiting', 'ready', 'fulfilled', 'expired', 'cancelled')),
+    ready_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX ux_holds_active_book_user ON holds(book_id, user_id) WHERE status IN ('waiting', 'ready');
+CREATE UNIQUE INDEX ux_holds_ready_book ON holds(book_id) WHERE status = 'ready';
+CREATE INDEX idx_holds_queue ON holds(book_id, created_at) WHERE status = 'waiting';
+CREATE INDEX idx_holds_expires_at ON holds(expires_at) WHERE status = 'ready';
+CREATE INDEX idx_holds_user_id ON holds(user_id);
@@ -0,0 +1 @@
+ALTER TABLE book_rental DROP COLUMN IF EXISTS renewals;
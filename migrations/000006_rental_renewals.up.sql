@@ -0,0 +1 @@
+ALTER TABLE book_rental ADD COLUMN renewals INTEGER NOT NULL DEFAULT 0 CHECK (renewals >= 0);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_books_genre;
+ALTER TABLE users DROP COLUMN IF EXISTS birth_date;
+ALTER TABLE books DROP COLUMN IF EXISTS genre;
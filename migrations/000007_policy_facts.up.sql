@@ -0,0 +1,3 @@
+ALTER TABLE books ADD COLUMN genre VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN birth_date DATE;
+CREATE INDEX idx_books_genre ON books(genre);
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN password_hash VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'patron' CHECK (role IN ('patron', 'librarian'));
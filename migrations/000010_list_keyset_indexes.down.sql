@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_book_rental_user_rental_date_id;
+DROP INDEX IF EXISTS idx_user_ledger_user_created_at_id;
+DROP INDEX IF EXISTS idx_users_created_at_id;
+DROP INDEX IF EXISTS idx_users_name_id;
+DROP INDEX IF EXISTS idx_authors_name_id;
+DROP INDEX IF EXISTS idx_books_created_at_id;
+DROP INDEX IF EXISTS idx_books_title_id;
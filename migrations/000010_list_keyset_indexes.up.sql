@@ -0,0 +1,7 @@
+CREATE INDEX idx_books_title_id ON books(title, id);
+CREATE INDEX idx_books_created_at_id ON books(created_at, id);
+CREATE INDEX idx_authors_name_id ON authors(name, id);
+CREATE INDEX idx_users_name_id ON users(name, id);
+CREATE INDEX idx_users_created_at_id ON users(created_at, id);
+CREATE INDEX idx_user_ledger_user_created_at_id ON user_ledger(user_id, created_at, id);
+CREATE INDEX idx_book_rental_user_rental_date_id ON book_rental(user_id, rental_date, id);
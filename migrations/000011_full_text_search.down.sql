@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_authors_search_ru;
+DROP INDEX IF EXISTS idx_authors_search_en;
+DROP INDEX IF EXISTS idx_books_search_ru;
+DROP INDEX IF EXISTS idx_books_search_en;
+ALTER TABLE authors DROP COLUMN IF EXISTS search_ru, DROP COLUMN IF EXISTS search_en;
+ALTER TABLE books DROP COLUMN IF EXISTS search_ru, DROP COLUMN IF EXISTS search_en;
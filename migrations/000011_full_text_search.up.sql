@@ -0,0 +1,18 @@
+ALTER TABLE books
+    ADD COLUMN search_en tsvector GENERATED ALWAYS AS (to_tsvector('english', title)) STORED,
+    ADD COLUMN search_ru tsvector GENERATED ALWAYS AS (to_tsvector('russian', title)) STORED;
+
+ALTER TABLE authors
+    ADD COLUMN search_en tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', name), 'A') ||
+        setweight(to_tsvector('english', COALESCE(biography, '')), 'B')
+    ) STORED,
+    ADD COLUMN search_ru tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', name), 'A') ||
+        setweight(to_tsvector('russian', COALESCE(biography, '')), 'B')
+    ) STORED;
+
+CREATE INDEX idx_books_search_en ON books USING GIN (search_en);
+CREATE INDEX idx_books_search_ru ON books USING GIN (search_ru);
+CREATE INDEX idx_authors_search_en ON authors USING GIN (search_en);
+CREATE INDEX idx_authors_search_ru ON authors USING GIN (search_ru);
@@ -0,0 +1,17 @@
+-- старые уникальные индексы восстановятся, только если у каждого названия
+-- не больше одной выдачи и готовой брони
+DROP TRIGGER IF EXISTS trg_book_copies_sync_available ON book_copies;
+DROP FUNCTION IF EXISTS book_copies_sync_available();
+
+DROP INDEX IF EXISTS idx_book_rental_copy_id;
+DROP INDEX IF EXISTS ux_holds_ready_copy;
+DROP INDEX IF EXISTS ux_book_rental_active_copy;
+
+ALTER TABLE holds DROP COLUMN IF EXISTS copy_id;
+ALTER TABLE book_rental DROP COLUMN IF EXISTS copy_id;
+DROP TABLE IF EXISTS book_copies;
+ALTER TABLE books DROP COLUMN IF EXISTS publication_year, DROP COLUMN IF EXISTS isbn;
+
+CREATE UNIQUE INDEX ux_holds_ready_book ON holds(book_id) WHERE status = 'ready';
+CREATE UNIQUE INDEX ux_unique_book_rental_book_id ON unique_book_rental(book_id);
+CREATE UNIQUE INDEX ux_book_rental_active_book ON book_rental(book_id) WHERE return_date IS NULL;
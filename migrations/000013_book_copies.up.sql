@@ -0,0 +1,61 @@
+ALTER TABLE books
+    ADD COLUMN isbn VARCHAR(17),
+    ADD COLUMN publication_year INTEGER CHECK (publication_year BETWEEN 1 AND 9999);
+
+CREATE TABLE book_copies (
+    id SERIAL PRIMARY KEY,
+    book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    barcode VARCHAR(32) NOT NULL UNIQUE,
+    available BOOLEAN NOT NULL DEFAULT TRUE,
+    retired_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (retired_at IS NULL OR NOT available)
+);
+CREATE INDEX idx_book_copies_book_id ON book_copies(book_id);
+
+-- каждая прежняя строка books остаётся отдельным названием с одним экземпляром со служебным штрихкодом.
+-- Одинаковые названия не сливаются: слияние дублей каталога - отдельный осознанный шаг
+INSERT INTO book_copies (book_id, barcode, available, created_at)
+SELECT id, 'B' || lpad(id::text, 9, '0'), available, created_at FROM books;
+
+ALTER TABLE book_rental ADD COLUMN copy_id INTEGER REFERENCES book_copies(id) ON DELETE CASCADE;
+UPDATE book_rental r SET copy_id = c.id FROM book_copies c WHERE c.book_id = r.book_id;
+ALTER TABLE book_rental ALTER COLUMN copy_id SET NOT NULL;
+
+-- готовая бронь держит на полке выдачи конкретный экземпляр
+ALTER TABLE holds ADD COLUMN copy_id INTEGER REFERENCES book_copies(id) ON DELETE SET NULL;
+UPDATE holds h SET copy_id = c.id FROM book_copies c WHERE c.book_id = h.book_id AND h.status = 'ready';
+
+-- у названия теперь может быть несколько одновременных выдач и готовых броней
+DROP INDEX ux_book_rental_active_book;
+DROP INDEX ux_unique_book_rental_book_id;
+DROP INDEX ux_holds_ready_book;
+
+CREATE UNIQUE INDEX ux_book_rental_active_copy ON book_rental(copy_id) WHERE return_date IS NULL;
+CREATE UNIQUE INDEX ux_holds_ready_copy ON holds(copy_id) WHERE status = 'ready';
+CREATE INDEX idx_book_rental_copy_id ON book_rental(copy_id);
+
+-- books.available означает, что на полке есть хотя бы один экземпляр; флаг поддерживает триггер
+CREATE FUNCTION book_copies_sync_available() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE books SET available = EXISTS (
+            SELECT 1 FROM book_copies WHERE book_id = OLD.book_id AND available
+        ) WHERE id = OLD.book_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE books SET available = EXISTS (
+            SELECT 1 FROM book_copies WHERE book_id = NEW.book_id AND available
+        ) WHERE id = NEW.book_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_book_copies_sync_available
+    AFTER INSERT OR UPDATE OR DELETE ON book_copies
+    FOR EACH ROW EXECUTE FUNCTION book_copies_sync_available();
+
+UPDATE books b SET available = EXISTS (
+    SELECT 1 FROM book_copies c WHERE c.book_id = b.id AND c.available
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS ux_books_isbn;
+ALTER TABLE books DROP CONSTRAINT IF EXISTS books_isbn_format;
+
+UPDATE books b SET isbn = c.isbn
+FROM isbn_cleanup c
+WHERE c.book_id = b.id;
+DROP TABLE IF EXISTS isbn_cleanup;
@@ -0,0 +1,61 @@
+-- приводит ISBN-10 или ISBN-13 с разделителями к ISBN-13 из одних цифр, NULL если контрольная цифра не сходится
+CREATE FUNCTION pg_temp.isbn13(raw TEXT) RETURNS TEXT AS $$
+DECLARE
+    s TEXT := regexp_replace(upper(raw), '[- ]', '', 'g');
+    total INTEGER := 0;
+BEGIN
+    IF s ~ '^[0-9]{9}[0-9X]$' THEN
+        FOR i IN 1..10 LOOP
+            total := total + (11 - i) * CASE WHEN substr(s, i, 1) = 'X' THEN 10 ELSE substr(s, i, 1)::INTEGER END;
+        END LOOP;
+        IF total % 11 <> 0 THEN
+            RETURN NULL;
+        END IF;
+        s := '978' || left(s, 9);
+        total := 0;
+        FOR i IN 1..12 LOOP
+            total := total + substr(s, i, 1)::INTEGER * CASE WHEN i % 2 = 1 THEN 1 ELSE 3 END;
+        END LOOP;
+        RETURN s || ((10 - total % 10) % 10)::TEXT;
+    END IF;
+
+    IF s ~ '^97[89][0-9]{10}$' THEN
+        FOR i IN 1..13 LOOP
+            total := total + substr(s, i, 1)::INTEGER * CASE WHEN i % 2 = 1 THEN 1 ELSE 3 END;
+        END LOOP;
+        IF total % 10 = 0 THEN
+            RETURN s;
+        END IF;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- невалидные номера очищаются, повторяющийся ISBN остаётся у книги, заведённой раньше.
+-- Очищаемые значения в исходном виде сохраняются в isbn_cleanup, чтобы их можно было разобрать вручную
+CREATE TABLE isbn_cleanup (
+    book_id INTEGER PRIMARY KEY REFERENCES books(id) ON DELETE CASCADE,
+    isbn VARCHAR(17) NOT NULL,
+    reason TEXT NOT NULL,
+    cleared_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO isbn_cleanup (book_id, isbn, reason)
+SELECT id, isbn, 'invalid'
+FROM books
+WHERE isbn IS NOT NULL AND pg_temp.isbn13(isbn) IS NULL;
+
+INSERT INTO isbn_cleanup (book_id, isbn, reason)
+SELECT b.id, b.isbn, 'duplicate of book ' || min(d.id)
+FROM books b
+JOIN books d ON pg_temp.isbn13(d.isbn) = pg_temp.isbn13(b.isbn) AND d.id < b.id
+GROUP BY b.id, b.isbn;
+
+UPDATE books SET isbn = pg_temp.isbn13(isbn) WHERE isbn IS NOT NULL;
+UPDATE books b SET isbn = NULL
+FROM books d
+WHERE d.isbn = b.isbn AND d.id < b.id;
+
+ALTER TABLE books ADD CONSTRAINT books_isbn_format CHECK (isbn ~ '^97[89][0-9]{10}$');
+CREATE UNIQUE INDEX ux_books_isbn ON books(isbn);
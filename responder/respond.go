@@ -3,24 +3,64 @@ package responder
 import (
 	"context"
 	"errors"
+	"library/internal/domain"
 	"net/http"
 
 	"github.com/ptflp/godecoder"
 	"go.uber.org/zap"
 )
 
-type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
+const problemContentType = "application/problem+json"
+
+// problemTypeBase - основа URI типа ошибки; к ней добавляется стабильный код ошибки
+const problemTypeBase = "/problems/"
+
+// Стабильные коды ошибок, если сама ошибка не сообщает свой код
+const (
+	CodeBadRequest   = "bad_request"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeNotFound     = "not_found"
+	CodeConflict     = "conflict"
+	CodeValidation   = "validation_failed"
+	CodeUnavailable  = "unavailable"
+	CodeInternal     = "internal"
+)
+
+// Problem - описание ошибки по RFC 7807
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     string              `json:"code"`
+	Errors   []domain.FieldError `json:"errors,omitempty"`
+	Details  interface{}         `json:"details,omitempty"`
 }
 
 type Responder interface {
 	OutputJSON(w http.ResponseWriter, responseData interface{})
-	ErrorUnauthorized(w http.ResponseWriter, err error)
-	ErrorBadRequest(w http.ResponseWriter, err error)
-	ErrorForbidden(w http.ResponseWriter, err error)
-	ErrorInternal(w http.ResponseWriter, err error)
+	Error(w http.ResponseWriter, r *http.Request, err error)
+	ErrorUnauthorized(w http.ResponseWriter, r *http.Request, err error)
+	ErrorBadRequest(w http.ResponseWriter, r *http.Request, err error)
+	ErrorForbidden(w http.ResponseWriter, r *http.Request, err error)
+	ErrorInternal(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// coder - ошибка со стабильным кодом для клиентов API
+type coder interface {
+	Code() string
+}
+
+// detailer - ошибка с дополнительными данными для клиента
+type detailer interface {
+	Details() interface{}
+}
+
+// fielder - ошибка проверки с разбивкой по полям запроса
+type fielder interface {
+	FieldErrors() []domain.FieldError
 }
 
 type Respond struct {
@@ -39,57 +79,83 @@ func (r *Respond) OutputJSON(w http.ResponseWriter, responseData interface{}) {
 	}
 }
 
-func (r *Respond) ErrorBadRequest(w http.ResponseWriter, err error) {
-	r.log.Info("http response bad request status code", zap.Error(err))
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	w.WriteHeader(http.StatusBadRequest)
-	if err := r.Encode(w, Response{
-		Success: false,
-		Message: err.Error(),
-		Data:    nil,
-	}); err != nil {
-		r.log.Info("response writer error on write", zap.Error(err))
+// Error выбирает статус ответа по виду доменной ошибки
+func (r *Respond) Error(w http.ResponseWriter, req *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		r.log.Info("http response not found", zap.Error(err))
+		r.problem(w, req, http.StatusNotFound, CodeNotFound, err)
+	case errors.Is(err, domain.ErrConflict):
+		r.log.Info("http response conflict", zap.Error(err))
+		r.problem(w, req, http.StatusConflict, CodeConflict, err)
+	case errors.Is(err, domain.ErrValidation):
+		r.log.Info("http response unprocessable entity", zap.Error(err))
+		r.problem(w, req, http.StatusUnprocessableEntity, CodeValidation, err)
+	case errors.Is(err, domain.ErrForbidden):
+		r.log.Warn("http resposne forbidden", zap.Error(err))
+		r.problem(w, req, http.StatusForbidden, CodeForbidden, err)
+	case errors.Is(err, domain.ErrUnavailable):
+		// как и для внутренних ошибок, текст ошибки базы остаётся только в журнале
+		r.log.Error("http response service unavailable", zap.Error(err), zap.String("instance", req.URL.Path))
+		w.Header().Set("Retry-After", "1")
+		r.problem(w, req, http.StatusServiceUnavailable, CodeUnavailable, errors.New("service temporarily unavailable, retry later"))
+	default:
+		r.ErrorInternal(w, req, err)
 	}
 }
 
-func (r *Respond) ErrorForbidden(w http.ResponseWriter, err error) {
+func (r *Respond) ErrorBadRequest(w http.ResponseWriter, req *http.Request, err error) {
+	r.log.Info("http response bad request status code", zap.Error(err))
+	r.problem(w, req, http.StatusBadRequest, CodeBadRequest, err)
+}
+
+func (r *Respond) ErrorForbidden(w http.ResponseWriter, req *http.Request, err error) {
 	r.log.Warn("http resposne forbidden", zap.Error(err))
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	w.WriteHeader(http.StatusForbidden)
-	if err := r.Encode(w, Response{
-		Success: false,
-		Message: err.Error(),
-		Data:    nil,
-	}); err != nil {
-		r.log.Error("response writer error on write", zap.Error(err))
-	}
+	r.problem(w, req, http.StatusForbidden, CodeForbidden, err)
 }
 
-func (r *Respond) ErrorUnauthorized(w http.ResponseWriter, err error) {
+func (r *Respond) ErrorUnauthorized(w http.ResponseWriter, req *http.Request, err error) {
 	r.log.Warn("http resposne Unauthorized", zap.Error(err))
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	w.WriteHeader(http.StatusUnauthorized)
-	if err := r.Encode(w, Response{
-		Success: false,
-		Message: err.Error(),
-		Data:    nil,
-	}); err != nil {
-		r.log.Error("response writer error on write", zap.Error(err))
-	}
+	w.Header().Set("WWW-Authenticate", `Bearer realm="library"`)
+	r.problem(w, req, http.StatusUnauthorized, CodeUnauthorized, err)
 }
 
-func (r *Respond) ErrorInternal(w http.ResponseWriter, err error) {
+// ErrorInternal не раскрывает клиенту текст внутренней ошибки, он остаётся только в журнале
+func (r *Respond) ErrorInternal(w http.ResponseWriter, req *http.Request, err error) {
 	if errors.Is(err, context.Canceled) {
 		return
 	}
-	r.log.Error("http response internal error", zap.Error(err))
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
-	w.WriteHeader(http.StatusInternalServerError)
-	if err := r.Encode(w, Response{
-		Success: false,
-		Message: err.Error(),
-		Data:    nil,
-	}); err != nil {
+	r.log.Error("http response internal error", zap.Error(err), zap.String("instance", req.URL.Path))
+	r.problem(w, req, http.StatusInternalServerError, CodeInternal, errors.New("internal server error"))
+}
+
+// problem отправляет описание ошибки; код, поля и подробности берутся из самой ошибки, если она их сообщает
+func (r *Respond) problem(w http.ResponseWriter, req *http.Request, status int, code string, err error) {
+	var c coder
+	if errors.As(err, &c) {
+		code = c.Code()
+	}
+
+	p := Problem{
+		Type:     problemTypeBase + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: req.URL.Path,
+		Code:     code,
+	}
+	var f fielder
+	if errors.As(err, &f) {
+		p.Errors = f.FieldErrors()
+	}
+	var d detailer
+	if errors.As(err, &d) {
+		p.Details = d.Details()
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(status)
+	if err := r.Encode(w, p); err != nil {
 		r.log.Error("response writer error on write", zap.Error(err))
 	}
 }
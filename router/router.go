@@ -1,43 +1,123 @@
 package router
 
 import (
+	"fmt"
+	"library/internal/auth"
+	"library/internal/domain"
 	"library/internal/handler"
+	"library/responder"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func NewApiRouter(authorController handler.Authorer, bookController handler.Booker, rentController handler.Rentaler, userController handler.Userer) http.Handler {
+func NewApiRouter(respond responder.Responder, mw *auth.Middleware, authController handler.Authenticator, authorController handler.Authorer, bookController handler.Booker, rentController handler.Rentaler, userController handler.Userer, ledgerController handler.Ledgerer, holdController handler.Holder, accountController handler.Accounter, apiKeyController handler.APIKeyer, searchController handler.Searcher, copyController handler.Copier, importController handler.Importer, marcController handler.MARCer, exportController handler.Exporter) http.Handler {
 	r := chi.NewRouter()
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		respond.Error(w, r, fmt.Errorf("route %s %w", r.URL.Path, domain.ErrNotFound))
+	})
 
 	r.Group(func(r chi.Router) {
-		r.Post("/author", authorController.CreateAuthor)
-		r.Get("/author/{authorId}", authorController.GetAuthor)
-		r.Get("/author/top", authorController.GetTopAuthors)
-		r.Get("/author/all", authorController.GetAllAuthors)
-		r.Delete("/author/{authorId}", authorController.DeleteAuthor)
-		r.Get("/author/books/{authorId}", authorController.GetByBooksAuthor)
-
+		r.Post("/auth/login", authController.Login)
+		r.Post("/auth/refresh", authController.Refresh)
 	})
 
 	r.Group(func(r chi.Router) {
-		r.Post("/book", bookController.AddBook)
-		r.Get("/book/{bookId}", bookController.GetBook)
-		r.Delete("/book/author/{authorId}", bookController.DeleteBook)
+		r.Use(mw.Authenticate)
+		// машинные клиенты проходят проверку роли, их доступ ограничивают области ключа
+		librarian := mw.RequireRole(domain.RoleLibrarian, domain.RoleService)
 
-	})
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope(domain.ScopeCatalog))
+			r.With(librarian).Post("/author", authorController.CreateAuthor)
+			r.Get("/author/{authorId}", authorController.GetAuthor)
+			r.Get("/author/top", authorController.GetTopAuthors)
+			r.Get("/author/all", authorController.GetAllAuthors)
+			r.With(librarian).Put("/author/{authorId}", authorController.UpdateAuthor)
+			r.With(librarian).Patch("/author/{authorId}", authorController.PatchAuthor)
+			r.With(librarian).Delete("/author/{authorId}", authorController.DeleteAuthor)
+			r.Get("/author/books/{authorId}", authorController.GetByBooksAuthor)
 
-	r.Group(func(r chi.Router) {
-		r.Post("/rental/{bookId}/{userId}", rentController.RentBook)
-		r.Delete("/rental/{bookId}", rentController.ReturnBook)
-	})
+		})
 
-	r.Group(func(r chi.Router) {
-		r.Post("/user", userController.Create)
-		r.Get("/user/{userId}", userController.GetByID)
-		r.Delete("/user/{userId}", userController.DeleteUser)
-		r.Get("/user/all", userController.GetAll)
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope(domain.ScopeCatalog))
+			r.With(librarian).Post("/book", bookController.AddBook)
+			r.With(librarian).Post("/book/import", importController.ImportBooks)
+			r.With(librarian).Post("/book/marc", marcController.ImportRecords)
+			r.Get("/book/marc", marcController.ExportBooks)
+			r.Get("/book", bookController.ListBooks)
+			r.Get("/book/{bookId}", bookController.GetBook)
+			r.Get("/book/isbn/{isbn}", bookController.GetBookByISBN)
+			r.Get("/book/{bookId}/marc", marcController.ExportBook)
+			r.With(librarian).Put("/book/{bookId}", bookController.UpdateBook)
+			r.With(librarian).Patch("/book/{bookId}", bookController.PatchBook)
+			r.With(librarian).Delete("/book/{bookId}", bookController.DeleteBook)
+			r.Get("/book/{bookId}/copies", copyController.ListCopies)
+			r.With(librarian).Post("/book/{bookId}/copies", copyController.AddCopy)
+			r.With(librarian).Delete("/copy/{copyId}", copyController.RetireCopy)
+			r.Get("/search", searchController.Search)
+			r.Get("/suggest", searchController.Suggest)
+
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope(domain.ScopeRental))
+			r.Post("/rental/{bookId}/{userId}", rentController.RentBook)
+			r.Delete("/rental/{bookId}", rentController.ReturnBook)
+			r.Delete("/rental/copy/{barcode}", rentController.ReturnCopy)
+			r.Post("/rental/{rentalId}/renew", rentController.RenewRental)
+			r.With(librarian).Get("/rental/overdue", rentController.ListOverdue)
+			r.Get("/user/{userId}/overdue", rentController.ListUserOverdue)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope(domain.ScopeUsers))
+			r.With(librarian).Post("/user", userController.Create)
+			r.Get("/user/{userId}", userController.GetByID)
+			r.Put("/user/{userId}", userController.UpdateUser)
+			r.Patch("/user/{userId}", userController.PatchUser)
+			r.With(librarian).Delete("/user/{userId}", userController.DeleteUser)
+			r.With(librarian).Get("/user/all", userController.GetAll)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope(domain.ScopeUsers))
+			r.Get("/user/{userId}/ledger", ledgerController.GetLedger)
+			r.With(librarian).Post("/user/{userId}/payments", ledgerController.Pay)
+			r.With(librarian).Post("/user/{userId}/waivers", ledgerController.Waive)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope(domain.ScopeRental))
+			r.Post("/hold/{bookId}/{userId}", holdController.PlaceHold)
+			r.Delete("/hold/{holdId}", holdController.CancelHold)
+			r.With(librarian).Get("/hold/book/{bookId}", holdController.ListBookHolds)
+			r.Get("/user/{userId}/holds", holdController.ListUserHolds)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireRole(domain.RolePatron, domain.RoleLibrarian))
+			r.Get("/me", accountController.Profile)
+			r.Get("/me/loans", accountController.Loans)
+			r.Get("/me/history", accountController.History)
+			r.Get("/me/holds", accountController.Holds)
+			r.Post("/me/loans/{bookId}", accountController.Checkout)
+			r.Delete("/me/loans/{bookId}", accountController.Return)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireRole(domain.RoleLibrarian))
+			r.Post("/apikey", apiKeyController.CreateKey)
+			r.Get("/apikey", apiKeyController.ListKeys)
+			r.Delete("/apikey/{keyId}", apiKeyController.RevokeKey)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireRole(domain.RoleLibrarian))
+			r.Get("/export/{dataset}", exportController.Export)
+		})
 	})
 
 	r.Get("/swagger/*", httpSwagger.Handler(
@@ -3,8 +3,11 @@ package run
 import (
 	"context"
 	"fmt"
+	"library/config"
+	"library/internal/auth"
 	"library/internal/facade"
 	"library/internal/handler"
+	"library/internal/policy"
 	"library/internal/repository"
 	"library/internal/usecase"
 	"library/responder"
@@ -15,6 +18,7 @@ import (
 
 	"net/http"
 	"os"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -30,6 +34,9 @@ const (
 	GeneralError
 )
 
+// holdExpiryInterval - период проверки просроченных броней
+const holdExpiryInterval = time.Minute
+
 // Application - интерфейс приложения
 type Application interface {
 	Runner
@@ -50,13 +57,17 @@ type Bootstraper interface {
 type App struct {
 	logger *zap.Logger
 	db     *sqlx.DB
+	conf   *config.LibraryConfig
+	auth   *config.AuthConfig
+	policy *policy.Config
+	facade facade.Facader
 	srv    *server.Server
 	Sig    chan os.Signal
 }
 
 // NewApp - конструктор приложения
-func NewApp(db *sqlx.DB, logger *zap.Logger) *App {
-	return &App{db: db, logger: logger, Sig: make(chan os.Signal, 1)}
+func NewApp(db *sqlx.DB, conf *config.LibraryConfig, authConf *config.AuthConfig, policy *policy.Config, logger *zap.Logger) *App {
+	return &App{db: db, conf: conf, auth: authConf, policy: policy, logger: logger, Sig: make(chan os.Signal, 1)}
 }
 
 // Run - запуск приложения
@@ -81,6 +92,21 @@ func (a *App) Run() int {
 		return nil
 	})
 
+	errGroup.Go(func() error {
+		ticker := time.NewTicker(holdExpiryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if err := a.facade.ExpireHolds(ctx); err != nil {
+					a.logger.Error("app: expire holds error", zap.Error(err))
+				}
+			}
+		}
+	})
+
 	if err := errGroup.Wait(); err != nil {
 		return GeneralError
 	}
@@ -98,29 +124,64 @@ func (a *App) Bootstrap(options ...interface{}) Runner {
 	respond := responder.NewResponder(decoder, a.logger)
 
 	authorRepo := repository.NewAuthorRepository(a.db)
-	bookRepo := repository.NewBookRepository(a.db, authorRepo)
+	bookRepo := repository.NewBookRepository(a.db)
+	copyRepo := repository.NewCopyRepository(a.db)
 	userRepo := repository.NewUserRepository(a.db)
 	rentRepo := repository.NewRentalRepository(a.db)
+	ledgerRepo := repository.NewLedgerRepository(a.db)
+	holdRepo := repository.NewHoldRepository(a.db)
+	apiKeyRepo := repository.NewAPIKeyRepository(a.db)
+	searchRepo := repository.NewSearchRepository(a.db)
+	importRepo := repository.NewImportRepository(a.db)
+	exportRepo := repository.NewExportRepository(a.db)
+	txManager := repository.NewTxManager(a.db)
 
 	userUC := usecase.NewUserUseCase(userRepo)
 	authorUC := usecase.NewAuthorUseCase(authorRepo)
-	bookUC := usecase.NewBookUseCase(bookRepo)
+	bookUC := usecase.NewBookUseCase(bookRepo, authorRepo)
+	copyUC := usecase.NewCopyUseCase(copyRepo)
 	rentUC := usecase.NewRentUseCase(rentRepo)
-
-	facade := facade.NewLibraryFacade(a.db, authorUC, bookUC, rentUC, userUC)
+	ledgerUC := usecase.NewLedgerUseCase(ledgerRepo, txManager)
+	holdUC := usecase.NewHoldUseCase(holdRepo)
+	policyEngine := policy.NewEngineFromConfig(a.policy)
+	tokens := auth.NewTokenManager(a.auth)
+	authUC := usecase.NewAuthUseCase(userRepo, tokens)
+	apiKeyUC := usecase.NewAPIKeyUseCase(apiKeyRepo)
+	searchUC := usecase.NewSearchUseCase(searchRepo)
+	importUC := usecase.NewImportUseCase(importRepo, txManager)
+	marcUC := usecase.NewMARCUseCase(bookRepo, authorRepo, txManager)
+	exportUC := usecase.NewExportUseCase(exportRepo)
+
+	a.facade = facade.NewLibraryFacade(a.db, txManager, a.conf, authorUC, bookUC, copyUC, rentUC, userUC, ledgerUC, holdUC, policyEngine)
 
 	ctx := context.Background()
-	err := facade.InitializeDataIfEmpty(ctx)
+	err := a.facade.InitializeDataIfEmpty(ctx)
 	if err != nil {
 		fmt.Println(err.Error())
 	}
+	// без библиотекаря сервисом некому управлять, поэтому ошибка здесь останавливает запуск
+	err = a.facade.EnsureLibrarian(ctx, a.auth.AdminEmail, a.auth.AdminPassword)
+	if err != nil {
+		a.logger.Fatal("app: ensure librarian account", zap.String("email", a.auth.AdminEmail), zap.Error(err))
+	}
 
+	authHandler := handler.NewAuthHandler(authUC, respond)
 	authorHandler := handler.NewAuthorHandler(authorUC, respond)
 	bookHandler := handler.NewBookHandler(bookUC, respond)
+	copyHandler := handler.NewCopyHandler(a.facade, respond)
+	importHandler := handler.NewImportHandler(importUC, respond)
+	marcHandler := handler.NewMARCHandler(marcUC, respond)
+	exportHandler := handler.NewExportHandler(exportUC, respond)
 	userHandler := handler.NewUserHandler(userUC, respond)
-	rentHandler := handler.NewRentHandler(facade, respond)
-
-	r := router.NewApiRouter(authorHandler, bookHandler, rentHandler, userHandler)
+	rentHandler := handler.NewRentHandler(a.facade, respond)
+	ledgerHandler := handler.NewLedgerHandler(ledgerUC, respond)
+	holdHandler := handler.NewHoldHandler(a.facade, respond)
+	accountHandler := handler.NewAccountHandler(userUC, a.facade, respond)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyUC, respond)
+	searchHandler := handler.NewSearchHandler(searchUC, respond)
+
+	mw := auth.NewMiddleware(tokens, apiKeyUC, respond, a.logger)
+	r := router.NewApiRouter(respond, mw, authHandler, authorHandler, bookHandler, rentHandler, userHandler, ledgerHandler, holdHandler, accountHandler, apiKeyHandler, searchHandler, copyHandler, importHandler, marcHandler, exportHandler)
 	a.srv = server.NewServer(r)
 
 	return a
@@ -0,0 +1,87 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"library/internal/repository"
+	"library/internal/usecase"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// Command - разовые команды обслуживания без запуска сервера: library <команда> [аргументы]
+func (a *App) Command(args []string) int {
+	ctx := context.Background()
+
+	switch args[0] {
+	case "import-csv":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "usage: import-csv FILE")
+			return GeneralError
+		}
+		return a.importCSV(ctx, args[1])
+	case "export":
+		if len(args) < 3 || len(args) > 4 {
+			fmt.Fprintln(os.Stderr, "usage: export books|authors|users|rentals csv|jsonl|excel [FILE]")
+			return GeneralError
+		}
+		path := ""
+		if len(args) == 4 {
+			path = args[3]
+		}
+		return a.export(ctx, args[1], args[2], path)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		return GeneralError
+	}
+}
+
+// importCSV загружает каталог из файла и печатает причины отказа по строкам и итог
+func (a *App) importCSV(ctx context.Context, path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		a.logger.Error("import: open file", zap.Error(err))
+		return GeneralError
+	}
+	defer file.Close()
+
+	importUC := usecase.NewImportUseCase(repository.NewImportRepository(a.db), repository.NewTxManager(a.db))
+	report, err := importUC.ImportCSV(ctx, file)
+	if err != nil {
+		a.logger.Error("import: load catalogue", zap.Error(err))
+		return InternalError
+	}
+
+	for _, line := range report.Lines {
+		for _, e := range line.Errors {
+			fmt.Printf("line %d: %s: %s\n", line.Line, e.Field, e.Message)
+		}
+	}
+	fmt.Printf("accepted %d, rejected %d, authors created %d\n", report.Accepted, report.Rejected, report.AuthorsCreated)
+	if report.Rejected > 0 {
+		return GeneralError
+	}
+	return NoError
+}
+
+// export пишет выгрузку в файл или, если он не указан, в стандартный вывод
+func (a *App) export(ctx context.Context, dataset, format, path string) int {
+	out := os.Stdout
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			a.logger.Error("export: create file", zap.Error(err))
+			return GeneralError
+		}
+		defer file.Close()
+		out = file
+	}
+
+	exportUC := usecase.NewExportUseCase(repository.NewExportRepository(a.db))
+	if err := exportUC.Export(ctx, dataset, format, out); err != nil {
+		a.logger.Error("export: write data", zap.String("dataset", dataset), zap.Error(err))
+		return InternalError
+	}
+	return NoError
+}